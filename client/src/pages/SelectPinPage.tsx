@@ -15,6 +15,7 @@ interface GameState {
     };
     room_id?: string;
     player_id?: string;
+    token?: string;
 }
 
 export const SelectPinPage = () => {
@@ -41,8 +42,8 @@ export const SelectPinPage = () => {
 
     // Connect WebSocket if multiplayer
     useEffect(() => {
-        if (state.mode === "multiplayer") {
-            socketService.connect();
+        if (state.mode === "multiplayer" && state.token) {
+            socketService.connect(state.token);
 
             // Listen for game_start
             const unsubscribe = socketService.subscribe((msg: WebSocketMessage) => {
@@ -161,8 +162,8 @@ export const SelectPinPage = () => {
         if (state.mode === "multiplayer") {
             setIsSubmitting(true);
             try {
-                if (state.room_id && state.player_id) {
-                    await api.submitPin(state.room_id, state.player_id, pins);
+                if (state.room_id && state.player_id && state.token) {
+                    await api.submitPin(state.room_id, state.player_id, state.token, pins);
                     setIsWaiting(true);
                 } else {
                     console.error("Missing room_id or player_id");
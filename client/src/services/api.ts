@@ -16,6 +16,9 @@ export interface JoinGameResponse {
     player_id: string;
     room_id: string;
     status: string;
+    token: string; // Session token for the WebSocket connection
+    profile_id: string; // Public ID of the profile the game is rated for
+    profile_token: string; // Lets this browser play as the same profile in later games
 }
 
 export interface SubmitPinPayload {
@@ -24,6 +27,35 @@ export interface SubmitPinPayload {
 
 const API_BASE_URL = import.meta.env.VITE_API_URL || 'https://api.lockpick.co';
 
+const PROFILE_TOKEN_KEY = 'lockpick_profile_token';
+
+// postWithProfile sends the stored profile token along with the payload, so the player keeps their rating
+// across games, and stores the token of the profile the server played them as.
+// A token the server no longer accepts is dropped and the request is sent again without it.
+const postWithProfile = async (path: string, payload: object): Promise<Response> => {
+    const profileToken = localStorage.getItem(PROFILE_TOKEN_KEY);
+    const send = (body: object) => fetch(`${API_BASE_URL}${path}`, {
+        method: 'POST',
+        headers: {
+            'Content-Type': 'application/json',
+        },
+        body: JSON.stringify(body),
+    });
+
+    let response = await send(profileToken ? { ...payload, profile_token: profileToken } : payload);
+    if (response.status === 401 && profileToken) {
+        localStorage.removeItem(PROFILE_TOKEN_KEY);
+        response = await send(payload);
+    }
+    return response;
+};
+
+const saveProfileToken = (data: JoinGameResponse) => {
+    if (data.profile_token) {
+        localStorage.setItem(PROFILE_TOKEN_KEY, data.profile_token);
+    }
+};
+
 export const api = {
     createGame: async (config: GameConfig): Promise<JoinGameResponse> => {
         const payload = {
@@ -31,42 +63,35 @@ export const api = {
             player_name: config.player_name
         };
 
-        const response = await fetch(`${API_BASE_URL}/games`, {
-            method: 'POST',
-            headers: {
-                'Content-Type': 'application/json',
-            },
-            body: JSON.stringify(payload),
-        });
+        const response = await postWithProfile('/games', payload);
 
         if (!response.ok) {
             throw new Error(`Failed to create game: ${response.statusText}`);
         }
 
-        return response.json();
+        const data: JoinGameResponse = await response.json();
+        saveProfileToken(data);
+        return data;
     },
 
     joinGame: async (payload: JoinPayload): Promise<JoinGameResponse> => {
-        const response = await fetch(`${API_BASE_URL}/games/join`, {
-            method: 'POST',
-            headers: {
-                'Content-Type': 'application/json',
-            },
-            body: JSON.stringify(payload),
-        });
+        const response = await postWithProfile('/games/join', payload);
 
         if (!response.ok) {
             throw new Error(`Failed to join game: ${response.statusText}`);
         }
 
-        return response.json();
+        const data: JoinGameResponse = await response.json();
+        saveProfileToken(data);
+        return data;
     },
 
-    submitPin: async (roomId: string, playerId: string, pins: string[]): Promise<void> => {
+    submitPin: async (roomId: string, playerId: string, token: string, pins: string[]): Promise<void> => {
         const response = await fetch(`${API_BASE_URL}/games/${roomId}/players/${playerId}/pin`, {
             method: 'POST',
             headers: {
                 'Content-Type': 'application/json',
+                'Authorization': `Bearer ${token}`,
             },
             body: JSON.stringify({ pins }),
         });
@@ -17,14 +17,15 @@ class WebSocketService {
         this.url = url;
     }
 
-    connect() {
+    // The session token returned when creating or joining a game identifies the player and room
+    connect(token: string) {
         if (this.socket && (this.socket.readyState === WebSocket.OPEN || this.socket.readyState === WebSocket.CONNECTING)) {
             console.log('WebSocket already connected or connecting');
             return;
         }
 
         console.log('Connecting to WebSocket:', this.url);
-        this.socket = new WebSocket(this.url);
+        this.socket = new WebSocket(`${this.url}?token=${encodeURIComponent(token)}`);
 
         this.socket.onopen = () => {
             console.log('WebSocket connected');
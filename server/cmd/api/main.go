@@ -9,11 +9,13 @@ import (
 	"syscall"
 	"time"
 
+	// docs is generated by Swag CLI, you have to import it.
 	_ "github.com/obasekietinosa/lockpick-api/docs"
 	"github.com/obasekietinosa/lockpick-api/internal/config"
+	"github.com/obasekietinosa/lockpick-api/internal/matchmaking"
 	"github.com/obasekietinosa/lockpick-api/internal/server"
 	"github.com/obasekietinosa/lockpick-api/internal/socket"
-	"github.com/obasekietinosa/lockpick-api/internal/store" // docs is generated by Swag CLI, you have to import it.
+	"github.com/obasekietinosa/lockpick-api/internal/store"
 )
 
 // @title Lockpick API
@@ -27,18 +29,26 @@ func main() {
 	// Load config
 	cfg := config.Load()
 
-	// Initialize Redis Store
-	redisStore, err := store.NewRedisStore(cfg.RedisAddr, cfg.RedisPassword)
-	if err != nil {
-		log.Fatalf("Failed to connect to Redis: %s", err)
-	}
+	// Initialize Store
+	gameStore := newStore(cfg)
 
 	// Initialize WebSocket Hub
-	hub := socket.NewHub(cfg, redisStore)
+	hub := socket.NewHub(cfg, gameStore)
+
+	// Pair players waiting for a public game as their tolerance widens.
+	// Hosts who close their connection leave the queue.
+	matchmaker := matchmaking.NewService(cfg, gameStore, hub)
+	hub.OnDisconnect(matchmaker.HostDisconnected)
 	go hub.Run()
 
+	// Clean up after abandoned games in the background
+	sweepCtx, stopSweeper := context.WithCancel(context.Background())
+	defer stopSweeper()
+	go hub.RunSweeper(sweepCtx, cfg.SweepInterval)
+	go matchmaker.Run(sweepCtx, time.Second)
+
 	// Initialize HTTP Server
-	srv := server.NewServer(cfg, hub, redisStore)
+	srv := server.NewServer(cfg, hub, gameStore, matchmaker)
 
 	// Start Server
 	go func() {
@@ -63,3 +73,38 @@ func main() {
 
 	log.Println("Server exiting")
 }
+
+// newStore creates the store selected by STORE_BACKEND, with pins encrypted at rest
+func newStore(cfg *config.Config) store.Store {
+	keys, err := store.ParsePinKeys(cfg.PinKeys)
+	if err != nil {
+		log.Fatalf("Invalid PIN_KEYS: %s", err)
+	}
+	encrypted, err := store.NewEncryptedStore(newBackend(cfg), keys)
+	if err != nil {
+		log.Fatalf("Invalid PIN_KEYS: %s", err)
+	}
+	return encrypted
+}
+
+// newBackend creates the store selected by STORE_BACKEND
+func newBackend(cfg *config.Config) store.Store {
+	switch cfg.StoreBackend {
+	case "memory":
+		log.Println("Using in-memory store, games are lost on restart")
+		return store.NewMemoryStore()
+	case "redis":
+		redisStore, err := store.NewRedisStore(cfg.RedisAddr, cfg.RedisPassword, store.TTLConfig{
+			Waiting:  cfg.WaitingRoomTTL,
+			Playing:  cfg.PlayingRoomTTL,
+			Finished: cfg.FinishedRoomTTL,
+		})
+		if err != nil {
+			log.Fatalf("Failed to connect to Redis: %s", err)
+		}
+		return redisStore
+	default:
+		log.Fatalf("Unknown STORE_BACKEND %q, expected redis or memory", cfg.StoreBackend)
+		return nil
+	}
+}
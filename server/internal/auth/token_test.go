@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSigner_SignAndVerify(t *testing.T) {
+	signer := NewSigner([]byte("secret"))
+
+	token := signer.Sign("room1", "player1")
+
+	session, err := signer.Verify(token)
+	if err != nil {
+		t.Fatalf("Expected token to verify, got %v", err)
+	}
+	if session.RoomID != "room1" || session.PlayerID != "player1" {
+		t.Errorf("Unexpected session: %+v", session)
+	}
+}
+
+func TestSigner_VerifyRejectsInvalidTokens(t *testing.T) {
+	signer := NewSigner([]byte("secret"))
+	valid := signer.Sign("room1", "player1")
+	other := NewSigner([]byte("other-secret")).Sign("room1", "player1")
+	forged := signer.Sign("room1", "player2")
+	validPayload, validSig, _ := strings.Cut(valid, ".")
+	forgedPayload, _, _ := strings.Cut(forged, ".")
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "Empty", token: ""},
+		{name: "No Signature", token: "abc"},
+		{name: "Wrong Key", token: other},
+		{name: "Tampered Payload", token: forgedPayload + "." + validSig},
+		{name: "Tampered Signature", token: validPayload + ".x" + validSig},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := signer.Verify(tt.token); err != ErrInvalidToken {
+				t.Errorf("Expected ErrInvalidToken, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSigner_SignAndVerifyProfile(t *testing.T) {
+	signer := NewSigner([]byte("secret"))
+
+	profileID, err := signer.VerifyProfile(signer.SignProfile("profile1"))
+	if err != nil {
+		t.Fatalf("Expected token to verify, got %v", err)
+	}
+	if profileID != "profile1" {
+		t.Errorf("Expected profile1, got %s", profileID)
+	}
+
+	// Session and profile tokens cannot stand in for each other
+	if _, err := signer.VerifyProfile(signer.Sign("room1", "player1")); err != ErrInvalidProfileToken {
+		t.Errorf("Expected a session token to be rejected as a profile token, got %v", err)
+	}
+	if _, err := signer.Verify(signer.SignProfile("profile1")); err != ErrInvalidToken {
+		t.Errorf("Expected a profile token to be rejected as a session token, got %v", err)
+	}
+	if _, err := signer.VerifyProfile(NewSigner([]byte("other-secret")).SignProfile("profile1")); err != ErrInvalidProfileToken {
+		t.Errorf("Expected a token signed with another key to be rejected, got %v", err)
+	}
+}
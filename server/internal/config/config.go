@@ -1,21 +1,86 @@
 package config
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
 	"os"
+	"strconv"
+	"time"
 )
 
 type Config struct {
 	Port          string
+	StoreBackend  string // "redis" (default) or "memory"
 	RedisAddr     string
 	RedisPassword string
+	SessionSecret string // HMAC key used to sign session and profile tokens
+	PinKeys       string // AES-256 keys used to encrypt pins at rest, as comma separated "id:hexkey" pairs, newest first
+
+	// Seconds a disconnected player has to reconnect before forfeiting the game
+	DisconnectGracePeriod int
+
+	// How long Redis keeps a game's keys in each state, refreshed whenever the game changes
+	WaitingRoomTTL  time.Duration
+	PlayingRoomTTL  time.Duration
+	FinishedRoomTTL time.Duration
+
+	// How often orphaned timers are cleaned up
+	SweepInterval time.Duration
+
+	// How long players wait in matchmaking before accepting a different timer or hint settings,
+	// and before they are taken out of the queue
+	MatchTimerTolerance time.Duration
+	MatchHintTolerance  time.Duration
+	MatchTimeout        time.Duration
 }
 
 func Load() *Config {
-	return &Config{
+	cfg := &Config{
 		Port:          getEnv("PORT", "8103"),
+		StoreBackend:  getEnv("STORE_BACKEND", "redis"),
 		RedisAddr:     getEnv("REDIS_ADDR", "localhost:6379"),
 		RedisPassword: getEnv("REDIS_PASSWORD", ""),
+		SessionSecret: getEnv("SESSION_SECRET", ""),
+		PinKeys:       getEnv("PIN_KEYS", ""),
+
+		DisconnectGracePeriod: getEnvInt("DISCONNECT_GRACE_PERIOD", 30),
+
+		WaitingRoomTTL:  getEnvDuration("WAITING_ROOM_TTL", 10*time.Minute),
+		PlayingRoomTTL:  getEnvDuration("PLAYING_ROOM_TTL", 2*time.Hour),
+		FinishedRoomTTL: getEnvDuration("FINISHED_ROOM_TTL", 24*time.Hour),
+		SweepInterval:   getEnvDuration("SWEEP_INTERVAL", time.Minute),
+
+		MatchTimerTolerance: getEnvDuration("MATCH_TIMER_TOLERANCE", 15*time.Second),
+		MatchHintTolerance:  getEnvDuration("MATCH_HINT_TOLERANCE", 30*time.Second),
+		MatchTimeout:        getEnvDuration("MATCH_TIMEOUT", 2*time.Minute),
+	}
+
+	// Without a configured secret, sessions only stay valid until the next restart
+	if cfg.SessionSecret == "" {
+		log.Println("SESSION_SECRET is not set, generating a random session secret")
+		cfg.SessionSecret = randomSecret()
 	}
+
+	// Pins stored in Redis outlive the process, so a generated key would leave them unreadable after a restart.
+	// The memory backend loses its pins on restart anyway.
+	if cfg.PinKeys == "" {
+		if cfg.StoreBackend != "memory" {
+			log.Fatalf("PIN_KEYS must be set when using the %s store", cfg.StoreBackend)
+		}
+		log.Println("PIN_KEYS is not set, generating a random pin key")
+		cfg.PinKeys = "generated:" + randomSecret()
+	}
+
+	return cfg
+}
+
+func randomSecret() string {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Fatalf("Failed to generate secret: %s", err)
+	}
+	return hex.EncodeToString(secret)
 }
 
 func getEnv(key, fallback string) string {
@@ -24,3 +89,29 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+func getEnvInt(key string, fallback int) int {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid value %q for %s, using %d", value, key, fallback)
+		return fallback
+	}
+	return n
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid duration %q for %s, using %s", value, key, fallback)
+		return fallback
+	}
+	return d
+}
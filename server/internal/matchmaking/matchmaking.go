@@ -0,0 +1,351 @@
+package matchmaking
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/obasekietinosa/lockpick-api/internal/auth"
+	"github.com/obasekietinosa/lockpick-api/internal/config"
+	"github.com/obasekietinosa/lockpick-api/internal/socket"
+	"github.com/obasekietinosa/lockpick-api/internal/store"
+)
+
+// roomSize is the number of players in a matched game
+const roomSize = 2
+
+// DefaultBandGrowth is how many rating points a rating band widens by for every second a ticket waits
+const DefaultBandGrowth = 5
+
+// Search describes the game a player is looking for
+type Search struct {
+	Config     *store.GameConfig
+	Rating     float64 // The player's profile rating
+	RatingBand float64 // Largest rating difference accepted from the start, 0 accepts any rating
+}
+
+// Ticket is a player waiting for an opponent in a room they host
+type Ticket struct {
+	Search
+	RoomID    string
+	PlayerID  string
+	CreatedAt time.Time
+}
+
+// Policy controls how the queue widens its tolerance the longer players wait
+type Policy struct {
+	TimerTolerance time.Duration // Wait after which timer and turn durations may differ
+	HintTolerance  time.Duration // Wait after which hint settings may differ
+	BandGrowth     float64       // Rating points a rating band widens by per second waited
+	Timeout        time.Duration // Wait after which a ticket is given up on, 0 waits forever
+}
+
+// Notifier pushes messages to the players in a room
+type Notifier interface {
+	BroadcastToRoom(roomID string, msg socket.GameMessage)
+}
+
+// Service pairs players looking for a public game.
+// Tickets are kept in memory, oldest first, so only one instance of the API can serve matchmaking
+// and waiting players are lost on restart.
+type Service struct {
+	mu       sync.Mutex
+	tickets  []*Ticket
+	store    store.Store
+	hub      Notifier
+	sessions *auth.Signer
+	policy   Policy
+	now      func() time.Time
+}
+
+func NewService(cfg *config.Config, store store.Store, hub Notifier) *Service {
+	return &Service{
+		store:    store,
+		hub:      hub,
+		sessions: auth.NewSigner([]byte(cfg.SessionSecret)),
+		policy: Policy{
+			TimerTolerance: cfg.MatchTimerTolerance,
+			HintTolerance:  cfg.MatchHintTolerance,
+			BandGrowth:     DefaultBandGrowth,
+			Timeout:        cfg.MatchTimeout,
+		},
+		now: time.Now,
+	}
+}
+
+// Join adds the player to the longest-waiting room that suits their search and tells its host.
+// It returns nil if nobody suitable is waiting, in which case the player should Enqueue a room of their own.
+func (s *Service) Join(ctx context.Context, search Search, player *store.Player) (*store.Room, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	newcomer := &Ticket{Search: search, PlayerID: player.ID, CreatedAt: now}
+	// Joining takes tickets out of the queue, so go through a copy of it
+	for _, t := range append([]*Ticket(nil), s.tickets...) {
+		if !s.policy.compatible(t, newcomer, now) {
+			continue
+		}
+		room, err := s.joinLocked(ctx, t, player)
+		if err != nil {
+			return nil, err
+		}
+		if room != nil {
+			return room, nil
+		}
+	}
+	return nil, nil
+}
+
+// Enqueue parks the host of a room in the queue until an opponent is found
+func (s *Service) Enqueue(room *store.Room, search Search) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tickets = append(s.tickets, &Ticket{Search: search, RoomID: room.ID, PlayerID: room.HostID, CreatedAt: s.now()})
+}
+
+// Cancel takes the room out of the queue. It reports whether the room was queued.
+func (s *Service) Cancel(roomID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.removeLocked(roomID)
+}
+
+// HostDisconnected takes the room out of the queue once its host has closed their last connection,
+// so nobody is matched with a player who has left. It suits Hub.OnDisconnect.
+func (s *Service) HostDisconnected(roomID, playerID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.indexLocked(roomID)
+	if i < 0 || s.tickets[i].PlayerID != playerID {
+		return
+	}
+	log.Printf("Host of room %s disconnected, leaving matchmaking", roomID)
+	s.removeLocked(roomID)
+}
+
+// QueueStats describes the players waiting for a match
+type QueueStats struct {
+	Waiting int           `json:"waiting"`
+	Buckets []BucketStats `json:"buckets"` // Ordered by key
+}
+
+// BucketStats describes the players waiting with the same settings
+type BucketStats struct {
+	Key           string `json:"key"` // GameConfig.MatchKey of the settings
+	Waiting       int    `json:"waiting"`
+	LongestWaitMs int64  `json:"longest_wait_ms"`
+}
+
+// Stats reports how many players are waiting for each combination of settings
+func (s *Service) Stats() QueueStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	stats := QueueStats{Waiting: len(s.tickets), Buckets: []BucketStats{}}
+	buckets := make(map[string]int)
+	for _, t := range s.tickets {
+		key := t.Config.MatchKey()
+		i, ok := buckets[key]
+		if !ok {
+			// Tickets are oldest first, so the first one of each bucket has waited longest
+			i = len(stats.Buckets)
+			buckets[key] = i
+			stats.Buckets = append(stats.Buckets, BucketStats{Key: key, LongestWaitMs: now.Sub(t.CreatedAt).Milliseconds()})
+		}
+		stats.Buckets[i].Waiting++
+	}
+	sort.Slice(stats.Buckets, func(i, j int) bool { return stats.Buckets[i].Key < stats.Buckets[j].Key })
+	return stats
+}
+
+// Run pairs waiting players whose tolerance has widened and times out tickets until ctx is cancelled
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.Tick(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Tick times out tickets that waited too long, then pairs the remaining ones
+func (s *Service) Tick(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.expireLocked(now)
+	for s.pairNextLocked(ctx, now) {
+	}
+}
+
+// pairNextLocked tries to pair the oldest ticket that has a match. Every attempt takes
+// at least one ticket out of the queue. It reports whether there was a pair to try.
+func (s *Service) pairNextLocked(ctx context.Context, now time.Time) bool {
+	for i, older := range s.tickets {
+		for _, newer := range s.tickets[i+1:] {
+			if s.policy.compatible(older, newer, now) {
+				s.pairLocked(ctx, older, newer)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *Service) expireLocked(now time.Time) {
+	if s.policy.Timeout <= 0 {
+		return
+	}
+
+	var kept []*Ticket
+	for _, t := range s.tickets {
+		if now.Sub(t.CreatedAt) < s.policy.Timeout {
+			kept = append(kept, t)
+			continue
+		}
+
+		log.Printf("Matchmaking ticket for room %s timed out", t.RoomID)
+		s.hub.BroadcastToRoom(t.RoomID, socket.GameMessage{
+			Type: "match_timeout",
+			Payload: map[string]interface{}{
+				"room_id": t.RoomID,
+			},
+		})
+	}
+	s.tickets = kept
+}
+
+// pairLocked moves the player of the newer ticket into the room of the older one
+func (s *Service) pairLocked(ctx context.Context, older, newer *Ticket) {
+	player, err := s.store.GetPlayer(ctx, newer.PlayerID)
+	if err != nil {
+		log.Printf("Error getting player %s for matchmaking: %v", newer.PlayerID, err)
+		s.removeLocked(newer.RoomID)
+		return
+	}
+
+	room, err := s.joinLocked(ctx, older, player)
+	if err != nil {
+		log.Printf("Error matching room %s: %v", older.RoomID, err)
+		return
+	}
+	if room == nil {
+		return
+	}
+	s.removeLocked(newer.RoomID)
+
+	// Nobody will play in the room the player leaves behind
+	err = store.RetryOnConflict(func() error {
+		abandoned, err := s.store.GetRoom(ctx, newer.RoomID)
+		if err != nil {
+			return err
+		}
+		abandoned.Status = "finished"
+		return s.store.SaveRoom(ctx, abandoned)
+	})
+	if err != nil {
+		log.Printf("Error closing room %s after matchmaking: %v", newer.RoomID, err)
+	}
+
+	// The player is still connected to their old room, so their new session is sent there
+	s.hub.BroadcastToRoom(newer.RoomID, matchFound(room, player.ID, room.HostID, s.sessions.Sign(room.ID, player.ID)))
+}
+
+// joinLocked adds the player to the room of the ticket and announces the match to its host.
+// The ticket leaves the queue either way. It returns nil if the room can no longer be joined.
+func (s *Service) joinLocked(ctx context.Context, t *Ticket, player *store.Player) (*store.Room, error) {
+	s.removeLocked(t.RoomID)
+
+	player.RoomID = t.RoomID
+	if err := s.store.JoinRoom(ctx, t.RoomID, player, roomSize); err != nil {
+		if errors.Is(err, store.ErrRoomFull) || errors.Is(err, store.ErrRoomNotWaiting) || errors.Is(err, store.ErrRoomNotFound) {
+			// Someone joined by room ID first, or the room closed or expired
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// The room stays waiting until both players have chosen their pins
+	room, err := s.store.GetRoom(ctx, t.RoomID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.hub.BroadcastToRoom(room.ID, matchFound(room, room.HostID, player.ID, ""))
+	return room, nil
+}
+
+// matchFound tells a player who they were matched with. token is only set for a player who changed rooms.
+func matchFound(room *store.Room, playerID, opponentID, token string) socket.GameMessage {
+	payload := map[string]interface{}{
+		"room_id":     room.ID,
+		"player_id":   playerID,
+		"opponent_id": opponentID,
+		"config":      room.Config,
+	}
+	if token != "" {
+		payload["token"] = token
+	}
+	return socket.GameMessage{Type: "match_found", Payload: payload}
+}
+
+func (s *Service) removeLocked(roomID string) bool {
+	i := s.indexLocked(roomID)
+	if i < 0 {
+		return false
+	}
+	s.tickets = append(s.tickets[:i], s.tickets[i+1:]...)
+	return true
+}
+
+func (s *Service) indexLocked(roomID string) int {
+	for i, t := range s.tickets {
+		if t.RoomID == roomID {
+			return i
+		}
+	}
+	return -1
+}
+
+// compatible reports whether two tickets can be matched. Settings are only relaxed once both players
+// have waited long enough, while each rating band widens with its own ticket's wait.
+func (p Policy) compatible(older, newer *Ticket, now time.Time) bool {
+	a, b := older.Config, newer.Config
+	waited := now.Sub(newer.CreatedAt)
+
+	if a.Mode != b.Mode || a.PinLength != b.PinLength || a.TotalRounds() != b.TotalRounds() ||
+		a.TurnBased != b.TurnBased || a.MaxGuesses != b.MaxGuesses {
+		return false
+	}
+	if waited < p.TimerTolerance && (a.TimerDuration != b.TimerDuration || a.TurnDuration != b.TurnDuration) {
+		return false
+	}
+	if waited < p.HintTolerance && (a.HintsEnabled != b.HintsEnabled || a.HintMode != b.HintMode) {
+		return false
+	}
+
+	return p.withinBand(older, newer.Rating, now) && p.withinBand(newer, older.Rating, now)
+}
+
+func (p Policy) withinBand(t *Ticket, rating float64, now time.Time) bool {
+	if t.RatingBand <= 0 {
+		return true
+	}
+	band := t.RatingBand + p.BandGrowth*now.Sub(t.CreatedAt).Seconds()
+	return math.Abs(t.Rating-rating) <= band
+}
@@ -0,0 +1,254 @@
+package matchmaking
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/obasekietinosa/lockpick-api/internal/auth"
+	"github.com/obasekietinosa/lockpick-api/internal/config"
+	"github.com/obasekietinosa/lockpick-api/internal/socket"
+	"github.com/obasekietinosa/lockpick-api/internal/store"
+)
+
+// recordingHub keeps the messages pushed to each room
+type recordingHub struct {
+	mu       sync.Mutex
+	messages map[string][]socket.GameMessage
+}
+
+func (h *recordingHub) BroadcastToRoom(roomID string, msg socket.GameMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.messages[roomID] = append(h.messages[roomID], msg)
+}
+
+func (h *recordingHub) last(roomID string) (socket.GameMessage, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	msgs := h.messages[roomID]
+	if len(msgs) == 0 {
+		return socket.GameMessage{}, false
+	}
+	return msgs[len(msgs)-1], true
+}
+
+type testQueue struct {
+	*Service
+	store *store.MemoryStore
+	hub   *recordingHub
+	clock time.Time
+}
+
+func newTestQueue() *testQueue {
+	cfg := &config.Config{
+		MatchTimerTolerance: 15 * time.Second,
+		MatchHintTolerance:  30 * time.Second,
+		MatchTimeout:        2 * time.Minute,
+	}
+	q := &testQueue{
+		store: store.NewMemoryStore(),
+		hub:   &recordingHub{messages: make(map[string][]socket.GameMessage)},
+		clock: time.Now(),
+	}
+	q.Service = NewService(cfg, q.store, q.hub)
+	q.Service.now = func() time.Time { return q.clock }
+	return q
+}
+
+// host creates a room for the player and queues it, like a public game that found no match
+func (q *testQueue) host(t *testing.T, playerID string, search Search) *store.Room {
+	t.Helper()
+	ctx := context.Background()
+	room := &store.Room{ID: "room-" + playerID, HostID: playerID, Status: "waiting", Config: search.Config, CurrentRound: 1}
+	if err := q.store.SaveRoom(ctx, room); err != nil {
+		t.Fatalf("SaveRoom: %v", err)
+	}
+	if err := q.store.JoinRoom(ctx, room.ID, &store.Player{ID: playerID, RoomID: room.ID}, roomSize); err != nil {
+		t.Fatalf("JoinRoom: %v", err)
+	}
+	q.Enqueue(room, search)
+	return room
+}
+
+func configWith(timer int, hints bool) *store.GameConfig {
+	return &store.GameConfig{PinLength: 4, TimerDuration: timer, HintsEnabled: hints}
+}
+
+func TestService_JoinExactMatch(t *testing.T) {
+	q := newTestQueue()
+	ctx := context.Background()
+
+	hosted := q.host(t, "p1", Search{Config: configWith(30, true)})
+
+	// Different settings are not accepted straight away
+	if room, err := q.Join(ctx, Search{Config: configWith(60, true)}, &store.Player{ID: "p2"}); err != nil || room != nil {
+		t.Fatalf("Expected no match for a different timer, got %v, %v", room, err)
+	}
+
+	room, err := q.Join(ctx, Search{Config: configWith(30, true)}, &store.Player{ID: "p3"})
+	if err != nil || room == nil || room.ID != hosted.ID {
+		t.Fatalf("Expected to join %s, got %v, %v", hosted.ID, room, err)
+	}
+	if room.Status != "waiting" {
+		t.Errorf("Expected matched room to wait for pins, got %s", room.Status)
+	}
+
+	msg, ok := q.hub.last(hosted.ID)
+	payload, _ := msg.Payload.(map[string]interface{})
+	if !ok || msg.Type != "match_found" || payload["opponent_id"] != "p3" {
+		t.Errorf("Expected match_found with opponent p3 for the host, got %+v", msg)
+	}
+
+	// The room left the queue
+	if room, _ := q.Join(ctx, Search{Config: configWith(30, true)}, &store.Player{ID: "p4"}); room != nil {
+		t.Errorf("Expected a matched room to leave the queue, joined %s", room.ID)
+	}
+}
+
+func TestService_TickWidensTolerance(t *testing.T) {
+	tests := []struct {
+		name      string
+		older     Search
+		newer     Search
+		wait      time.Duration
+		wantMatch bool
+	}{
+		{name: "Different Timer Too Soon", older: Search{Config: configWith(30, true)}, newer: Search{Config: configWith(60, true)}, wait: 10 * time.Second},
+		{name: "Different Timer After Tolerance", older: Search{Config: configWith(30, true)}, newer: Search{Config: configWith(60, true)}, wait: 15 * time.Second, wantMatch: true},
+		{name: "Different Hints Too Soon", older: Search{Config: configWith(30, true)}, newer: Search{Config: configWith(30, false)}, wait: 15 * time.Second},
+		{name: "Different Hints After Tolerance", older: Search{Config: configWith(30, true)}, newer: Search{Config: configWith(30, false)}, wait: 30 * time.Second, wantMatch: true},
+		{name: "Different Pin Length", older: Search{Config: configWith(30, true)}, newer: Search{Config: &store.GameConfig{PinLength: 6, TimerDuration: 30, HintsEnabled: true}}, wait: time.Minute},
+		{name: "Outside Rating Band", older: Search{Config: configWith(30, true), Rating: 1500, RatingBand: 100}, newer: Search{Config: configWith(30, true), Rating: 1700}, wait: 10 * time.Second},
+		{name: "Rating Band Widened", older: Search{Config: configWith(30, true), Rating: 1500, RatingBand: 100}, newer: Search{Config: configWith(30, true), Rating: 1700}, wait: 20 * time.Second, wantMatch: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newTestQueue()
+			ctx := context.Background()
+
+			older := q.host(t, "p1", tt.older)
+			newer := q.host(t, "p2", tt.newer)
+
+			q.clock = q.clock.Add(tt.wait)
+			q.Tick(ctx)
+
+			player, _ := q.store.GetPlayer(ctx, "p2")
+			if matched := player.RoomID == older.ID; matched != tt.wantMatch {
+				t.Fatalf("Expected matched = %v, player is in %s", tt.wantMatch, player.RoomID)
+			}
+			if !tt.wantMatch {
+				if len(q.tickets) != 2 {
+					t.Errorf("Expected both tickets to stay queued, got %d", len(q.tickets))
+				}
+				return
+			}
+
+			if len(q.tickets) != 0 {
+				t.Errorf("Expected the queue to be empty, got %d tickets", len(q.tickets))
+			}
+
+			// The moved player learns about their new room through the room they were waiting in
+			msg, _ := q.hub.last(newer.ID)
+			payload, _ := msg.Payload.(map[string]interface{})
+			if msg.Type != "match_found" || payload["room_id"] != older.ID {
+				t.Fatalf("Expected match_found for %s, got %+v", older.ID, msg)
+			}
+			session, err := auth.NewSigner(nil).Verify(payload["token"].(string))
+			if err != nil || session.RoomID != older.ID || session.PlayerID != "p2" {
+				t.Errorf("Expected a session for p2 in %s, got %+v, %v", older.ID, session, err)
+			}
+
+			abandoned, _ := q.store.GetRoom(ctx, newer.ID)
+			if abandoned.Status != "finished" {
+				t.Errorf("Expected the room left behind to be finished, got %s", abandoned.Status)
+			}
+		})
+	}
+}
+
+func TestService_Timeout(t *testing.T) {
+	q := newTestQueue()
+	ctx := context.Background()
+
+	room := q.host(t, "p1", Search{Config: configWith(30, true)})
+
+	q.clock = q.clock.Add(time.Minute)
+	q.Tick(ctx)
+	if len(q.tickets) != 1 {
+		t.Fatalf("Expected the ticket to wait, got %d tickets", len(q.tickets))
+	}
+
+	q.clock = q.clock.Add(time.Minute)
+	q.Tick(ctx)
+	if len(q.tickets) != 0 {
+		t.Fatalf("Expected the ticket to time out, got %d tickets", len(q.tickets))
+	}
+	if msg, _ := q.hub.last(room.ID); msg.Type != "match_timeout" {
+		t.Errorf("Expected match_timeout, got %s", msg.Type)
+	}
+}
+
+func TestService_Cancel(t *testing.T) {
+	q := newTestQueue()
+	ctx := context.Background()
+
+	room := q.host(t, "p1", Search{Config: configWith(30, true)})
+
+	if !q.Cancel(room.ID) {
+		t.Fatal("Expected the room to be cancelled")
+	}
+	if q.Cancel(room.ID) {
+		t.Error("Expected a second cancel to find nothing queued")
+	}
+	if joined, _ := q.Join(ctx, Search{Config: configWith(30, true)}, &store.Player{ID: "p2"}); joined != nil {
+		t.Errorf("Expected a cancelled room not to be joined, joined %s", joined.ID)
+	}
+}
+
+func TestService_HostDisconnected(t *testing.T) {
+	q := newTestQueue()
+	ctx := context.Background()
+
+	room := q.host(t, "p1", Search{Config: configWith(30, true)})
+
+	// Only the host leaving takes the room out of the queue
+	q.HostDisconnected(room.ID, "someone-else")
+	if len(q.tickets) != 1 {
+		t.Fatalf("Expected the room to stay queued, got %d tickets", len(q.tickets))
+	}
+
+	q.HostDisconnected(room.ID, "p1")
+	if len(q.tickets) != 0 {
+		t.Fatalf("Expected the room to leave the queue, got %d tickets", len(q.tickets))
+	}
+	if joined, _ := q.Join(ctx, Search{Config: configWith(30, true)}, &store.Player{ID: "p2"}); joined != nil {
+		t.Errorf("Expected nobody to be matched with a disconnected host, joined %s", joined.ID)
+	}
+}
+
+func TestService_Stats(t *testing.T) {
+	q := newTestQueue()
+
+	q.host(t, "p1", Search{Config: configWith(30, true)})
+	q.clock = q.clock.Add(5 * time.Second)
+	q.host(t, "p2", Search{Config: configWith(30, true)})
+	q.host(t, "p3", Search{Config: configWith(60, true)})
+
+	stats := q.Stats()
+	if stats.Waiting != 3 || len(stats.Buckets) != 2 {
+		t.Fatalf("Expected 3 players in 2 buckets, got %+v", stats)
+	}
+
+	want := map[string]BucketStats{
+		configWith(30, true).MatchKey(): {Key: configWith(30, true).MatchKey(), Waiting: 2, LongestWaitMs: 5000},
+		configWith(60, true).MatchKey(): {Key: configWith(60, true).MatchKey(), Waiting: 1, LongestWaitMs: 0},
+	}
+	for _, bucket := range stats.Buckets {
+		if bucket != want[bucket.Key] {
+			t.Errorf("Expected bucket %+v, got %+v", want[bucket.Key], bucket)
+		}
+	}
+}
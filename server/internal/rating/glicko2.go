@@ -0,0 +1,116 @@
+// Package rating implements the Glicko-2 rating system described by Mark Glickman
+// in "Example of the Glicko-2 system" (http://www.glicko.net/glicko/glicko2.pdf).
+package rating
+
+import "math"
+
+const (
+	// scale converts between the Glicko and Glicko-2 scales
+	scale = 173.7178
+	// tau constrains how much the volatility can change in one rating period
+	tau = 0.5
+	// epsilon is the convergence tolerance when solving for the new volatility
+	epsilon = 0.000001
+)
+
+// Scores of a single game, from the rated player's point of view
+const (
+	Win  = 1.0
+	Draw = 0.5
+	Loss = 0.0
+)
+
+// Rating is a player's skill estimate. Deviation is how uncertain the estimate is,
+// and Volatility how erratic the player's results have been.
+type Rating struct {
+	Rating     float64
+	Deviation  float64
+	Volatility float64
+}
+
+// Initial is the rating given to players who have not played a rated game yet
+var Initial = Rating{Rating: 1500, Deviation: 350, Volatility: 0.06}
+
+// Result is the outcome of one game against an opponent
+type Result struct {
+	Opponent Rating
+	Score    float64 // Win, Draw or Loss
+}
+
+// Update returns the player's rating after a rating period with the given results.
+// A period without results only grows the deviation.
+func Update(player Rating, results []Result) Rating {
+	mu := (player.Rating - 1500) / scale
+	phi := player.Deviation / scale
+	sigma := player.Volatility
+
+	if len(results) == 0 {
+		phi = math.Sqrt(phi*phi + sigma*sigma)
+		return Rating{Rating: player.Rating, Deviation: phi * scale, Volatility: sigma}
+	}
+
+	// Estimated variance of the rating based on game outcomes, and the estimated improvement
+	var variance, improvement float64
+	for _, r := range results {
+		muJ := (r.Opponent.Rating - 1500) / scale
+		gJ := g(r.Opponent.Deviation / scale)
+		e := expected(mu, muJ, gJ)
+		variance += gJ * gJ * e * (1 - e)
+		improvement += gJ * (r.Score - e)
+	}
+	v := 1 / variance
+	delta := v * improvement
+
+	sigma = volatility(phi, sigma, v, delta)
+
+	phiStar := math.Sqrt(phi*phi + sigma*sigma)
+	phi = 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	mu += phi * phi * improvement
+
+	return Rating{Rating: mu*scale + 1500, Deviation: phi * scale, Volatility: sigma}
+}
+
+func g(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+// expected is the probability of a player rated mu beating an opponent rated muJ
+func expected(mu, muJ, gJ float64) float64 {
+	return 1 / (1 + math.Exp(-gJ*(mu-muJ)))
+}
+
+// volatility solves for the new volatility with the Illinois algorithm (step 5 of the paper)
+func volatility(phi, sigma, v, delta float64) float64 {
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + v + ex
+		return ex*(delta*delta-phi*phi-v-ex)/(2*d*d) - (x-a)/(tau*tau)
+	}
+
+	A := a
+	var B float64
+	if delta*delta > phi*phi+v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*tau) < 0 {
+			k++
+		}
+		B = a - k*tau
+	}
+
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > epsilon {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+		B, fB = C, fC
+	}
+
+	return math.Exp(A / 2)
+}
@@ -0,0 +1,54 @@
+package rating
+
+import (
+	"math"
+	"testing"
+)
+
+func TestUpdate_PaperExample(t *testing.T) {
+	// The worked example from Glickman's paper
+	player := Rating{Rating: 1500, Deviation: 200, Volatility: 0.06}
+	results := []Result{
+		{Opponent: Rating{Rating: 1400, Deviation: 30, Volatility: 0.06}, Score: Win},
+		{Opponent: Rating{Rating: 1550, Deviation: 100, Volatility: 0.06}, Score: Loss},
+		{Opponent: Rating{Rating: 1700, Deviation: 300, Volatility: 0.06}, Score: Loss},
+	}
+
+	got := Update(player, results)
+	want := Rating{Rating: 1464.06, Deviation: 151.52, Volatility: 0.05999}
+	if math.Abs(got.Rating-want.Rating) > 0.01 || math.Abs(got.Deviation-want.Deviation) > 0.01 || math.Abs(got.Volatility-want.Volatility) > 0.00001 {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	tests := []struct {
+		name  string
+		score float64
+		check func(before, after Rating) bool
+	}{
+		{name: "Win Raises Rating", score: Win, check: func(before, after Rating) bool { return after.Rating > before.Rating }},
+		{name: "Loss Lowers Rating", score: Loss, check: func(before, after Rating) bool { return after.Rating < before.Rating }},
+		{name: "Draw Between Equals Keeps Rating", score: Draw, check: func(before, after Rating) bool { return math.Abs(after.Rating-before.Rating) < 0.001 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			after := Update(Initial, []Result{{Opponent: Initial, Score: tt.score}})
+			if !tt.check(Initial, after) {
+				t.Errorf("Unexpected rating %+v", after)
+			}
+			if after.Deviation >= Initial.Deviation {
+				t.Errorf("Expected a game to lower the deviation, got %f", after.Deviation)
+			}
+		})
+	}
+}
+
+func TestUpdate_NoGames(t *testing.T) {
+	player := Rating{Rating: 1600, Deviation: 50, Volatility: 0.06}
+	after := Update(player, nil)
+	if after.Rating != player.Rating || after.Deviation <= player.Deviation {
+		t.Errorf("Expected only the deviation to grow, got %+v", after)
+	}
+}
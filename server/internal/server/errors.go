@@ -0,0 +1,30 @@
+package server
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/obasekietinosa/lockpick-api/internal/store"
+)
+
+// writeStoreError responds with the status code matching an error returned by the store
+func writeStoreError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, store.ErrRoomNotFound):
+		http.Error(w, "Room not found", http.StatusNotFound)
+	case errors.Is(err, store.ErrPlayerNotFound):
+		http.Error(w, "Player not found", http.StatusNotFound)
+	case errors.Is(err, store.ErrProfileNotFound):
+		http.Error(w, "Profile not found", http.StatusNotFound)
+	case errors.Is(err, store.ErrRoomFull):
+		http.Error(w, "Room is full", http.StatusConflict)
+	case errors.Is(err, store.ErrRoomNotWaiting):
+		http.Error(w, "Game has already started", http.StatusConflict)
+	case errors.Is(err, store.ErrConflict):
+		http.Error(w, "The game was changed by another request, please retry", http.StatusConflict)
+	default:
+		log.Printf("Store error: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/obasekietinosa/lockpick-api/internal/store"
+)
+
+func TestWriteStoreError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"Room Not Found", store.ErrRoomNotFound, http.StatusNotFound},
+		{"Player Not Found", store.ErrPlayerNotFound, http.StatusNotFound},
+		{"Room Full", store.ErrRoomFull, http.StatusConflict},
+		{"Conflict", store.ErrConflict, http.StatusConflict},
+		{"Wrapped", fmt.Errorf("joining: %w", store.ErrRoomFull), http.StatusConflict},
+		{"Other", errors.New("connection refused"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			writeStoreError(w, tt.err)
+			if w.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d", tt.expected, w.Code)
+			}
+		})
+	}
+}
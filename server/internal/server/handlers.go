@@ -1,39 +1,55 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/obasekietinosa/lockpick-api/internal/auth"
+	"github.com/obasekietinosa/lockpick-api/internal/matchmaking"
 	"github.com/obasekietinosa/lockpick-api/internal/socket"
 	"github.com/obasekietinosa/lockpick-api/internal/store"
 )
 
 type CreateGameRequest struct {
-	PlayerName string            `json:"player_name"`
-	Config     *store.GameConfig `json:"config"`
+	PlayerName   string            `json:"player_name"`
+	Config       *store.GameConfig `json:"config"`
+	ProfileToken string            `json:"profile_token,omitempty"` // Profile to play as, a new one is created when empty
+	RatingBand   float64           `json:"rating_band,omitempty"`   // Largest rating difference to accept at first, 0 accepts anyone
 }
 
 type JoinGameRequest struct {
-	PlayerName string `json:"player_name"`
-	RoomID     string `json:"room_id"`
+	PlayerName   string `json:"player_name"`
+	RoomID       string `json:"room_id"`
+	ProfileToken string `json:"profile_token,omitempty"` // Profile to play as, a new one is created when empty
 }
 
 type CreateGameResponse struct {
-	RoomID   string            `json:"room_id"`
-	PlayerID string            `json:"player_id"`
-	Status   string            `json:"status"`
-	Config   *store.GameConfig `json:"config,omitempty"`
+	RoomID       string            `json:"room_id"`
+	PlayerID     string            `json:"player_id"`
+	Status       string            `json:"status"`
+	Config       *store.GameConfig `json:"config,omitempty"`
+	Token        string            `json:"token"`         // Session token for the WebSocket connection
+	ProfileID    string            `json:"profile_id"`    // Public ID to look the profile up by
+	ProfileToken string            `json:"profile_token"` // Keep it private, it lets anyone play as this profile
 }
 
 type JoinGameResponse struct {
-	RoomID   string            `json:"room_id"`
-	PlayerID string            `json:"player_id"`
-	Status   string            `json:"status"`
-	Config   *store.GameConfig `json:"config"`
+	RoomID       string            `json:"room_id"`
+	PlayerID     string            `json:"player_id"`
+	Status       string            `json:"status"`
+	Config       *store.GameConfig `json:"config"`
+	Token        string            `json:"token"`         // Session token for the WebSocket connection
+	ProfileID    string            `json:"profile_id"`    // Public ID to look the profile up by
+	ProfileToken string            `json:"profile_token"` // Keep it private, it lets anyone play as this profile
 }
 
 // @Summary Create a new game
@@ -56,53 +72,72 @@ func (s *Server) HandleCreateGame(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !socket.IsValidHintMode(req.Config.HintMode) {
+		http.Error(w, fmt.Sprintf("Unsupported hint mode: %s", req.Config.HintMode), http.StatusBadRequest)
+		return
+	}
+
+	if !socket.IsValidGameMode(req.Config.Mode) {
+		http.Error(w, fmt.Sprintf("Unsupported game mode: %s", req.Config.Mode), http.StatusBadRequest)
+		return
+	}
+
+	if req.Config.Rounds < 0 || req.Config.Rounds > store.MaxRounds {
+		http.Error(w, fmt.Sprintf("Rounds must be between 1 and %d, or 0 for the default of %d", store.MaxRounds, store.DefaultRounds), http.StatusBadRequest)
+		return
+	}
+
+	if req.Config.TurnBased && socket.IsSolo(req.Config) {
+		http.Error(w, "Turn-based play is not available in solo games", http.StatusBadRequest)
+		return
+	}
+
+	if req.Config.PinLength <= 0 && socket.IsSolo(req.Config) {
+		http.Error(w, "Pin length must be positive", http.StatusBadRequest)
+		return
+	}
+
+	profile, err := s.playerProfile(r.Context(), req.ProfileToken, req.PlayerName)
+	if errors.Is(err, auth.ErrInvalidProfileToken) {
+		http.Error(w, "Invalid profile token", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	if socket.IsSolo(req.Config) {
+		s.createSoloGame(w, r, req, profile)
+		return
+	}
+
+	search := matchmaking.Search{Config: req.Config, Rating: profile.Rating, RatingBand: req.RatingBand}
+
 	// Logic for Random Matchmaking
 	if !req.Config.IsPrivate {
-		// Try to find a matching room
-		room, err := s.store.FindMatchingRoom(r.Context(), req.Config)
+		// Try to join the longest-waiting player whose settings suit this game
+		player := &store.Player{
+			ID:        uuid.New().String(),
+			Name:      req.PlayerName,
+			ProfileID: profile.ID,
+		}
+		room, err := s.matchmaker.Join(r.Context(), search, player)
 		if err != nil {
 			http.Error(w, "Error finding matching room", http.StatusInternalServerError)
 			return
 		}
-
 		if room != nil {
-			// Match found! Join this room.
-			// Create Player ID
-			playerID := uuid.New().String()
-			player := &store.Player{
-				ID:     playerID,
-				Name:   req.PlayerName,
-				RoomID: room.ID,
-			}
-
-			if err := s.store.SavePlayer(r.Context(), player); err != nil {
-				http.Error(w, "Failed to create player", http.StatusInternalServerError)
-				return
-			}
-
-			if err := s.store.AddPlayerToRoom(r.Context(), room.ID, player.ID); err != nil {
-				http.Error(w, "Failed to join room", http.StatusInternalServerError)
-				return
-			}
-
-			// Remove from waiting list
-			if err := s.store.RemoveWaitingRoom(r.Context(), room.ID); err != nil {
-				// Log error but proceed?
-			}
-
-			// Update room status? Ideally we set it to something else, but for now "waiting" -> "matched" on client side
-			// Or we update room.Status = "playing"
-			room.Status = "playing"
-			if err := s.store.SaveRoom(r.Context(), room); err != nil {
-				// Non-critical if fails, but good to have
-			}
-
+			// Match found!
 			w.Header().Set("Content-Type", "application/json")
 			json.NewEncoder(w).Encode(CreateGameResponse{
-				RoomID:   room.ID,
-				PlayerID: playerID,
-				Status:   "matched",
-				Config:   room.Config,
+				RoomID:       room.ID,
+				PlayerID:     player.ID,
+				Status:       "matched",
+				Config:       room.Config,
+				Token:        s.sessions.Sign(room.ID, player.ID),
+				ProfileID:    profile.ID,
+				ProfileToken: s.sessions.SignProfile(profile.ID),
 			})
 			return
 		}
@@ -113,9 +148,10 @@ func (s *Server) HandleCreateGame(w http.ResponseWriter, r *http.Request) {
 	playerID := uuid.New().String()
 
 	player := &store.Player{
-		ID:     playerID,
-		Name:   req.PlayerName,
-		RoomID: roomID,
+		ID:        playerID,
+		Name:      req.PlayerName,
+		RoomID:    roomID,
+		ProfileID: profile.ID,
 	}
 
 	room := &store.Room{
@@ -144,17 +180,135 @@ func (s *Server) HandleCreateGame(w http.ResponseWriter, r *http.Request) {
 
 	// Add to waiting list if it's a random search game
 	if !req.Config.IsPrivate {
-		if err := s.store.AddWaitingRoom(r.Context(), room); err != nil {
-			http.Error(w, "Failed to add to matchmaking", http.StatusInternalServerError)
+		s.matchmaker.Enqueue(room, search)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CreateGameResponse{
+		RoomID:       room.ID,
+		PlayerID:     playerID,
+		Status:       "waiting",
+		Token:        s.sessions.Sign(room.ID, playerID),
+		ProfileID:    profile.ID,
+		ProfileToken: s.sessions.SignProfile(profile.ID),
+	})
+}
+
+type LeaveMatchmakingResponse struct {
+	RoomID string `json:"room_id"`
+	Status string `json:"status"`
+}
+
+// @Summary Leave matchmaking
+// @Description Take a public game out of the matchmaking queue. The room can still be joined by its ID.
+// @Tags matchmaking
+// @Produce json
+// @Param gameID path string true "Game ID (Room ID)"
+// @Param Authorization header string true "Bearer session token"
+// @Success 200 {object} LeaveMatchmakingResponse
+// @Router /games/{gameID}/matchmaking [delete]
+func (s *Server) HandleLeaveMatchmaking(w http.ResponseWriter, r *http.Request) {
+	roomID := r.PathValue("gameID")
+
+	session, err := s.requestSession(r)
+	if err != nil || session == nil || session.RoomID != roomID {
+		http.Error(w, "Invalid session token", http.StatusUnauthorized)
+		return
+	}
+
+	if !s.matchmaker.Cancel(roomID) {
+		http.Error(w, "Game is not in matchmaking", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(LeaveMatchmakingResponse{
+		RoomID: roomID,
+		Status: "left_matchmaking",
+	})
+}
+
+// @Summary Matchmaking stats
+// @Description Number of players waiting in matchmaking, per combination of game settings
+// @Tags matchmaking
+// @Produce json
+// @Success 200 {object} matchmaking.QueueStats
+// @Router /matchmaking/stats [get]
+func (s *Server) HandleMatchmakingStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.matchmaker.Stats())
+}
+
+// maxPlayersPerRoom is the number of players in a game
+const maxPlayersPerRoom = 2
+
+// houseName is the display name of the server's player in solo games
+const houseName = "Lockpick"
+
+// createSoloGame creates a room against a server player whose pins are generated randomly.
+// There is no pin selection step, so the first round starts immediately.
+// Solo games are not rated, the profile is only returned so the client can keep using it.
+func (s *Server) createSoloGame(w http.ResponseWriter, r *http.Request, req CreateGameRequest, profile *store.Profile) {
+	pins, err := s.gameLogic.GeneratePins(req.Config.TotalRounds(), req.Config.PinLength)
+	if err != nil {
+		http.Error(w, "Failed to generate pins", http.StatusInternalServerError)
+		return
+	}
+
+	roomID := uuid.New().String()
+	playerID := uuid.New().String()
+
+	player := &store.Player{
+		ID:        playerID,
+		Name:      req.PlayerName,
+		RoomID:    roomID,
+		ProfileID: profile.ID,
+	}
+	house := &store.Player{
+		ID:     uuid.New().String(),
+		Name:   houseName,
+		RoomID: roomID,
+		Pins:   pins,
+	}
+
+	room := &store.Room{
+		ID:           roomID,
+		HostID:       playerID,
+		Status:       "playing",
+		Config:       req.Config,
+		CurrentRound: 1,
+		CreatedAt:    time.Now(),
+	}
+	socket.OpenRound(room, room.CreatedAt)
+
+	for _, p := range []*store.Player{player, house} {
+		if err := s.store.SavePlayer(r.Context(), p); err != nil {
+			http.Error(w, "Failed to create player", http.StatusInternalServerError)
+			return
+		}
+		if err := s.store.AddPlayerToRoom(r.Context(), roomID, p.ID); err != nil {
+			http.Error(w, "Failed to add player to room", http.StatusInternalServerError)
 			return
 		}
 	}
 
+	if err := s.store.SaveRoom(r.Context(), room); err != nil {
+		http.Error(w, "Failed to create room", http.StatusInternalServerError)
+		return
+	}
+
+	// Start Round 1
+	s.hub.StartRound(room.ID)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(CreateGameResponse{
-		RoomID:   room.ID,
-		PlayerID: playerID,
-		Status:   "waiting",
+		RoomID:       room.ID,
+		PlayerID:     playerID,
+		Status:       "playing",
+		Config:       room.Config,
+		Token:        s.sessions.Sign(room.ID, playerID),
+		ProfileID:    profile.ID,
+		ProfileToken: s.sessions.SignProfile(profile.ID),
 	})
 }
 
@@ -181,45 +335,46 @@ func (s *Server) HandleJoinGame(w http.ResponseWriter, r *http.Request) {
 	// Check if room exists
 	room, err := s.store.GetRoom(r.Context(), req.RoomID)
 	if err != nil {
-		http.Error(w, "Room not found", http.StatusNotFound)
+		writeStoreError(w, err)
 		return
 	}
 
-	// Check if room is full (optional, for now we just add)
-	players, err := s.store.GetRoomPlayers(r.Context(), req.RoomID)
-	if err != nil {
-		http.Error(w, "Failed to check room players", http.StatusInternalServerError)
+	profile, err := s.playerProfile(r.Context(), req.ProfileToken, req.PlayerName)
+	if errors.Is(err, auth.ErrInvalidProfileToken) {
+		http.Error(w, "Invalid profile token", http.StatusUnauthorized)
 		return
 	}
-
-	if len(players) >= 2 {
-		http.Error(w, "Room is full", http.StatusConflict) // 409 Conflict
+	if err != nil {
+		writeStoreError(w, err)
 		return
 	}
 
 	playerID := uuid.New().String()
 	player := &store.Player{
-		ID:     playerID,
-		Name:   req.PlayerName,
-		RoomID: req.RoomID,
+		ID:        playerID,
+		Name:      req.PlayerName,
+		RoomID:    req.RoomID,
+		ProfileID: profile.ID,
 	}
 
-	if err := s.store.SavePlayer(r.Context(), player); err != nil {
-		http.Error(w, "Failed to create player", http.StatusInternalServerError)
+	// Checking the player count and joining happen atomically, so concurrent joins cannot overfill the room
+	if err := s.store.JoinRoom(r.Context(), req.RoomID, player, maxPlayersPerRoom); err != nil {
+		writeStoreError(w, err)
 		return
 	}
 
-	if err := s.store.AddPlayerToRoom(r.Context(), req.RoomID, playerID); err != nil {
-		http.Error(w, "Failed to join room", http.StatusInternalServerError)
-		return
-	}
+	// A public room joined by ID is no longer open for matchmaking
+	s.matchmaker.Cancel(req.RoomID)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(JoinGameResponse{
-		RoomID:   room.ID,
-		PlayerID: playerID,
-		Status:   "joined",
-		Config:   room.Config,
+		RoomID:       room.ID,
+		PlayerID:     playerID,
+		Status:       "joined",
+		Config:       room.Config,
+		Token:        s.sessions.Sign(room.ID, playerID),
+		ProfileID:    profile.ID,
+		ProfileToken: s.sessions.SignProfile(profile.ID),
 	})
 }
 
@@ -228,16 +383,18 @@ type SelectPinRequest struct {
 }
 
 type SelectPinResponse struct {
-	Status string `json:"status"`
+	Status string   `json:"status"`
+	Pins   []string `json:"pins,omitempty"` // Only set when the pins were generated by the server
 }
 
 // @Summary Select pins for the game
-// @Description Select pins for all rounds of the game
+// @Description Select pins for all rounds of the game, one per round. Pins can only be chosen once, before the game starts.
 // @Tags games
 // @Accept json
 // @Produce json
 // @Param gameID path string true "Game ID (Room ID)"
 // @Param playerID path string true "Player ID"
+// @Param Authorization header string true "Bearer session token"
 // @Param request body SelectPinRequest true "Selected pins"
 // @Success 200 {object} SelectPinResponse
 // @Router /games/{gameID}/players/{playerID}/pin [post]
@@ -245,22 +402,32 @@ func (s *Server) HandleSelectPin(w http.ResponseWriter, r *http.Request) {
 	roomID := r.PathValue("gameID")
 	playerID := r.PathValue("playerID")
 
-	var req SelectPinRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !s.authorizePlayer(r, roomID, playerID) {
+		http.Error(w, "Invalid session token", http.StatusUnauthorized)
 		return
 	}
 
-	// Validate number of pins (should be 3 for 3 rounds)
-	if len(req.Pins) != 3 {
-		http.Error(w, "Exactly 3 pins are required", http.StatusBadRequest)
+	var req SelectPinRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
 	// Fetch room to check config
 	room, err := s.store.GetRoom(r.Context(), roomID)
 	if err != nil {
-		http.Error(w, "Room not found", http.StatusNotFound)
+		writeStoreError(w, err)
+		return
+	}
+
+	// Validate number of pins (one per round)
+	if len(req.Pins) != room.Config.TotalRounds() {
+		http.Error(w, fmt.Sprintf("Exactly %d pins are required", room.Config.TotalRounds()), http.StatusBadRequest)
+		return
+	}
+
+	if socket.IsSolo(room.Config) {
+		http.Error(w, "Pins are generated by the server in solo games", http.StatusBadRequest)
 		return
 	}
 
@@ -282,7 +449,7 @@ func (s *Server) HandleSelectPin(w http.ResponseWriter, r *http.Request) {
 	// Fetch Player
 	player, err := s.store.GetPlayer(r.Context(), playerID)
 	if err != nil {
-		http.Error(w, "Player not found", http.StatusNotFound)
+		writeStoreError(w, err)
 		return
 	}
 
@@ -292,6 +459,11 @@ func (s *Server) HandleSelectPin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if pinsLocked(room, player) {
+		http.Error(w, "Pins can no longer be changed", http.StatusConflict)
+		return
+	}
+
 	// Generate hints if hints are enabled? No, hints are generated during gameplay.
 	// But we might want to validate something else? No.
 
@@ -302,75 +474,289 @@ func (s *Server) HandleSelectPin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if all players have selected pins
-	roomPlayers, err := s.store.GetRoomPlayers(r.Context(), roomID)
+	s.startGameIfReady(r.Context(), room.ID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(SelectPinResponse{
+		Status: "pins_selected",
+	})
+}
+
+// startGameIfReady starts the game once every player in the room has selected their pins.
+// Both players can select their pins at the same time, so the room is only started by whoever saves it first.
+func (s *Server) startGameIfReady(ctx context.Context, roomID string) {
+	err := store.RetryOnConflict(func() error {
+		return s.tryStartGame(ctx, roomID)
+	})
+	if err != nil {
+		// Pins are already saved, so only log the error
+		log.Printf("Error saving room status: %v", err)
+	}
+}
+
+func (s *Server) tryStartGame(ctx context.Context, roomID string) error {
+	room, err := s.store.GetRoom(ctx, roomID)
 	if err != nil {
-		// Log error but don't fail the request? Or maybe fail?
-		// Stick to success for now, but logged.
-		fmt.Printf("Error getting room players: %v\n", err)
-	} else {
-		log.Printf("Checking if all players ready. Player count: %d", len(roomPlayers))
-		if len(roomPlayers) == 2 {
-			allReady := true
-			for _, pid := range roomPlayers {
-				p, err := s.store.GetPlayer(r.Context(), pid)
-				if err != nil || len(p.Pins) != 3 {
-					allReady = false
-					break
-				}
-			}
+		log.Printf("Error getting room: %v", err)
+		return nil
+	}
 
-			log.Printf("All players found: %v, All Ready: %v", roomPlayers, allReady)
-
-			if allReady {
-				log.Println("All players ready. Starting game...")
-				// Update room status
-				room.Status = "playing"
-				if err := s.store.SaveRoom(r.Context(), room); err != nil {
-					fmt.Printf("Error saving room status: %v\n", err)
-				}
-
-				// Broadcast Game Start
-				msg := socket.GameMessage{
-					Type: "game_start",
-					Payload: map[string]interface{}{
-						"room_id": room.ID,
-						"status":  "playing",
-					},
-				}
-				msgBytes, _ := json.Marshal(msg)
-				log.Printf("Broadcasting game_start message to room %s", room.ID)
-				s.hub.Broadcast <- msgBytes
-
-				// Start the timer for Round 1
-				s.hub.StartRoundTimer(room.ID)
-			}
+	// Another request may have started the game already
+	if room.RoundActive || !room.RoundStartedAt.IsZero() || room.Status == "finished" {
+		return nil
+	}
+
+	roomPlayers, err := s.store.GetRoomPlayers(ctx, room.ID)
+	if err != nil {
+		log.Printf("Error getting room players: %v", err)
+		return nil
+	}
+
+	log.Printf("Checking if all players ready. Player count: %d", len(roomPlayers))
+	if len(roomPlayers) != 2 {
+		return nil
+	}
+
+	for _, pid := range roomPlayers {
+		p, err := s.store.GetPlayer(ctx, pid)
+		if err != nil || len(p.Pins) != room.Config.TotalRounds() {
+			return nil
 		}
 	}
 
+	log.Println("All players ready. Starting game...")
+	// Update room status, round 1 starts immediately
+	room.Status = "playing"
+	socket.OpenRound(room, time.Now())
+	if err := s.store.SaveRoom(ctx, room); err != nil {
+		return err
+	}
+
+	// Broadcast Game Start
+	msg := socket.GameMessage{
+		Type: "game_start",
+		Payload: map[string]interface{}{
+			"room_id": room.ID,
+			"status":  "playing",
+		},
+	}
+	log.Printf("Broadcasting game_start message to room %s", room.ID)
+	s.hub.BroadcastToRoom(room.ID, msg)
+
+	// Start Round 1
+	s.hub.StartRound(room.ID)
+	return nil
+}
+
+// @Summary Generate random pins for the game
+// @Description Generate random pins for all rounds of the game. The pins are only returned to the player they belong to.
+// @Tags games
+// @Produce json
+// @Param gameID path string true "Game ID (Room ID)"
+// @Param playerID path string true "Player ID"
+// @Param Authorization header string true "Bearer session token"
+// @Success 200 {object} SelectPinResponse
+// @Router /games/{gameID}/players/{playerID}/pin/random [post]
+func (s *Server) HandleRandomPin(w http.ResponseWriter, r *http.Request) {
+	roomID := r.PathValue("gameID")
+	playerID := r.PathValue("playerID")
+
+	if !s.authorizePlayer(r, roomID, playerID) {
+		http.Error(w, "Invalid session token", http.StatusUnauthorized)
+		return
+	}
+
+	room, err := s.store.GetRoom(r.Context(), roomID)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	if socket.IsSolo(room.Config) {
+		http.Error(w, "Pins are generated by the server in solo games", http.StatusBadRequest)
+		return
+	}
+
+	player, err := s.store.GetPlayer(r.Context(), playerID)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	// Verify player belongs to room
+	if player.RoomID != roomID {
+		http.Error(w, "Player does not belong to this room", http.StatusForbidden)
+		return
+	}
+
+	if pinsLocked(room, player) {
+		http.Error(w, "Pins can no longer be changed", http.StatusConflict)
+		return
+	}
+
+	pins, err := s.gameLogic.GeneratePins(room.Config.TotalRounds(), room.Config.PinLength)
+	if err != nil {
+		http.Error(w, "Failed to generate pins", http.StatusInternalServerError)
+		return
+	}
+
+	player.Pins = pins
+	if err := s.store.SavePlayer(r.Context(), player); err != nil {
+		http.Error(w, "Failed to save pins", http.StatusInternalServerError)
+		return
+	}
+
+	s.startGameIfReady(r.Context(), room.ID)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(SelectPinResponse{
 		Status: "pins_selected",
+		Pins:   pins,
 	})
 }
 
+// pinsLocked reports whether the player can no longer choose pins. Pins are chosen once, before the game starts,
+// so they cannot be swapped for new ones after the opponent has started narrowing them down.
+func pinsLocked(room *store.Room, player *store.Player) bool {
+	return room.Status != "waiting" || len(player.Pins) > 0
+}
+
+// authorizePlayer checks that the request carries a session token for the given player and room
+func (s *Server) authorizePlayer(r *http.Request, roomID, playerID string) bool {
+	session, err := s.requestSession(r)
+	if err != nil || session == nil {
+		return false
+	}
+	return session.RoomID == roomID && session.PlayerID == playerID
+}
+
+// requestSession verifies the session token the request carries. It returns a nil session if there is none.
+func (s *Server) requestSession(r *http.Request) (*auth.Session, error) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return nil, nil
+	}
+	return s.sessions.Verify(token)
+}
+
 // @Summary Get game state
-// @Description Get current game state
+// @Description Get current game state. Pins are only shown once their round has finished.
+// @Description With a session token, `player` holds the authenticated player and all of their own pins.
 // @Tags games
 // @Accept json
 // @Produce json
 // @Param gameID path string true "Game ID (Room ID)"
-// @Success 200 {object} store.Room
+// @Param Authorization header string false "Bearer session token"
+// @Success 200 {object} PrivateRoom
 // @Router /games/{gameID} [get]
 func (s *Server) HandleGetGame(w http.ResponseWriter, r *http.Request) {
 	roomID := r.PathValue("gameID")
 
+	session, err := s.requestSession(r)
+	if err != nil || (session != nil && session.RoomID != roomID) {
+		http.Error(w, "Invalid session token", http.StatusUnauthorized)
+		return
+	}
+
 	room, err := s.store.GetRoom(r.Context(), roomID)
 	if err != nil {
-		http.Error(w, "Room not found", http.StatusNotFound)
+		writeStoreError(w, err)
 		return
 	}
 
+	players, err := s.roomPlayers(r.Context(), room)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	view := newPublicRoom(room, players)
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(room)
+	if session == nil {
+		json.NewEncoder(w).Encode(view)
+		return
+	}
+
+	private := PrivateRoom{PublicRoom: view}
+	for _, p := range players {
+		if p.ID == session.PlayerID {
+			private.Player = newPrivatePlayer(room, p)
+		}
+	}
+	json.NewEncoder(w).Encode(private)
+}
+
+// roomPlayers loads the players in the room, host first
+func (s *Server) roomPlayers(ctx context.Context, room *store.Room) ([]*store.Player, error) {
+	ids, err := s.store.GetRoomPlayers(ctx, room.ID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(ids)
+
+	players := make([]*store.Player, 0, len(ids))
+	for _, id := range ids {
+		p, err := s.store.GetPlayer(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if id == room.HostID {
+			players = append([]*store.Player{p}, players...)
+		} else {
+			players = append(players, p)
+		}
+	}
+	return players, nil
+}
+
+type ListGuessesResponse struct {
+	RoomID  string               `json:"room_id"`
+	Round   int                  `json:"round"`
+	Guesses []*store.GuessRecord `json:"guesses"`
+}
+
+// @Summary List guesses for a round
+// @Description Get every guess made in a round, with the hints it received, in the order they were made
+// @Tags games
+// @Produce json
+// @Param gameID path string true "Game ID (Room ID)"
+// @Param round path int true "Round number (1-indexed)"
+// @Success 200 {object} ListGuessesResponse
+// @Router /games/{gameID}/rounds/{round}/guesses [get]
+func (s *Server) HandleListGuesses(w http.ResponseWriter, r *http.Request) {
+	roomID := r.PathValue("gameID")
+
+	round, err := strconv.Atoi(r.PathValue("round"))
+	if err != nil {
+		http.Error(w, "Round must be a number", http.StatusBadRequest)
+		return
+	}
+
+	room, err := s.store.GetRoom(r.Context(), roomID)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	if round < 1 || round > room.Config.TotalRounds() {
+		http.Error(w, fmt.Sprintf("Round must be between 1 and %d", room.Config.TotalRounds()), http.StatusBadRequest)
+		return
+	}
+
+	guesses, err := s.store.ListGuesses(r.Context(), roomID, round)
+	if err != nil {
+		http.Error(w, "Failed to list guesses", http.StatusInternalServerError)
+		return
+	}
+	if guesses == nil {
+		guesses = []*store.GuessRecord{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ListGuessesResponse{
+		RoomID:  roomID,
+		Round:   round,
+		Guesses: guesses,
+	})
 }
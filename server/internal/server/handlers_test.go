@@ -4,83 +4,141 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/obasekietinosa/lockpick-api/internal/auth"
 	"github.com/obasekietinosa/lockpick-api/internal/config"
+	"github.com/obasekietinosa/lockpick-api/internal/matchmaking"
 	"github.com/obasekietinosa/lockpick-api/internal/socket"
 	"github.com/obasekietinosa/lockpick-api/internal/store"
 )
 
-// MockStore
+// MockStore is safe for concurrent use so that handlers can be exercised in parallel
 type MockStore struct {
-	rooms   map[string]*store.Room
-	players map[string]*store.Player
-	waiting map[string]string // simplified: key -> roomID
+	mu       sync.Mutex
+	rooms    map[string]*store.Room
+	players  map[string]*store.Player
+	guesses  map[string][]*store.GuessRecord
+	profiles map[string]*store.Profile
 }
 
 func NewMockStore() *MockStore {
 	return &MockStore{
-		rooms:   make(map[string]*store.Room),
-		players: make(map[string]*store.Player),
-		waiting: make(map[string]string),
+		rooms:    make(map[string]*store.Room),
+		players:  make(map[string]*store.Player),
+		guesses:  make(map[string][]*store.GuessRecord),
+		profiles: make(map[string]*store.Profile),
 	}
 }
 
 func (m *MockStore) SaveRoom(ctx context.Context, room *store.Room) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if stored, ok := m.rooms[room.ID]; ok && stored.Version != room.Version {
+		return store.ErrConflict
+	}
+	room.Version++
 	m.rooms[room.ID] = room
 	return nil
 }
 func (m *MockStore) GetRoom(ctx context.Context, roomID string) (*store.Room, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if r, ok := m.rooms[roomID]; ok {
 		return r, nil
 	}
-	return nil, nil // error handling simplified
+	return nil, store.ErrRoomNotFound
 }
 func (m *MockStore) SavePlayer(ctx context.Context, player *store.Player) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.players[player.ID] = player
 	return nil
 }
 func (m *MockStore) GetPlayer(ctx context.Context, playerID string) (*store.Player, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if p, ok := m.players[playerID]; ok {
 		return p, nil
 	}
-	return nil, nil
+	return nil, store.ErrPlayerNotFound
 }
 func (m *MockStore) AddPlayerToRoom(ctx context.Context, roomID, playerID string) error {
 	return nil
 }
+func (m *MockStore) JoinRoom(ctx context.Context, roomID string, player *store.Player, maxPlayers int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	room, ok := m.rooms[roomID]
+	if !ok {
+		return store.ErrRoomNotFound
+	}
+	if room.Status != "waiting" {
+		return store.ErrRoomNotWaiting
+	}
+	if len(m.roomPlayers(roomID)) >= maxPlayers {
+		return store.ErrRoomFull
+	}
+	m.players[player.ID] = player
+	return nil
+}
 func (m *MockStore) GetRoomPlayers(ctx context.Context, roomID string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.roomPlayers(roomID), nil
+}
+func (m *MockStore) roomPlayers(roomID string) []string {
 	var players []string
 	for _, p := range m.players {
 		if p.RoomID == roomID {
 			players = append(players, p.ID)
 		}
 	}
-	return players, nil
-}
-func (m *MockStore) FindMatchingRoom(ctx context.Context, config *store.GameConfig) (*store.Room, error) {
-	// Simple key generation for mock
-	key := "mock_key"
-	if id, ok := m.waiting[key]; ok {
-		delete(m.waiting, key) // Remove (pop)
-		return m.rooms[id], nil
-	}
-	return nil, nil
+	return players
 }
-func (m *MockStore) AddWaitingRoom(ctx context.Context, room *store.Room) error {
-	m.waiting["mock_key"] = room.ID
+func (m *MockStore) AppendGuess(ctx context.Context, roomID string, round int, guess *store.GuessRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := fmt.Sprintf("%s:%d", roomID, round)
+	m.guesses[key] = append(m.guesses[key], guess)
 	return nil
 }
-func (m *MockStore) RemoveWaitingRoom(ctx context.Context, roomID string) error {
+func (m *MockStore) ListGuesses(ctx context.Context, roomID string, round int) ([]*store.GuessRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.guesses[fmt.Sprintf("%s:%d", roomID, round)], nil
+}
+func (m *MockStore) SaveProfile(ctx context.Context, profile *store.Profile) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if stored, ok := m.profiles[profile.ID]; ok && stored.Version != profile.Version {
+		return store.ErrConflict
+	}
+	profile.Version++
+	copied := *profile
+	m.profiles[profile.ID] = &copied
 	return nil
 }
+func (m *MockStore) GetProfile(ctx context.Context, profileID string) (*store.Profile, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if p, ok := m.profiles[profileID]; ok {
+		copied := *p
+		return &copied, nil
+	}
+	return nil, store.ErrProfileNotFound
+}
 
 func TestHandleCreateGame(t *testing.T) {
 	mockStore := NewMockStore()
 	hub := socket.NewHub(&config.Config{}, mockStore)
-	srv := NewServer(&config.Config{}, hub, mockStore)
+	srv := NewServer(&config.Config{}, hub, mockStore, matchmaking.NewService(&config.Config{}, mockStore, hub))
 
 	// Test Case 1: Private Game
 	config := &store.GameConfig{
@@ -103,19 +161,189 @@ func TestHandleCreateGame(t *testing.T) {
 	if w.Code != http.StatusOK {
 		t.Errorf("Expected status 200, got %d", w.Code)
 	}
+
+	var resp CreateGameResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	session, err := auth.NewSigner(nil).Verify(resp.Token)
+	if err != nil {
+		t.Fatalf("Expected a valid session token, got %v", err)
+	}
+	if session.RoomID != resp.RoomID || session.PlayerID != resp.PlayerID {
+		t.Errorf("Token session %+v does not match response", session)
+	}
+}
+
+func TestHandleCreateGame_Matchmaking(t *testing.T) {
+	mockStore := NewMockStore()
+	hub := socket.NewHub(&config.Config{}, mockStore)
+	go hub.Run()
+	srv := NewServer(&config.Config{}, hub, mockStore, matchmaking.NewService(&config.Config{}, mockStore, hub))
+
+	create := func(name string) CreateGameResponse {
+		reqBody, _ := json.Marshal(CreateGameRequest{
+			PlayerName: name,
+			Config:     &store.GameConfig{PinLength: 5, TimerDuration: 30},
+		})
+		req := httptest.NewRequest("POST", "/games", bytes.NewBuffer(reqBody))
+		w := httptest.NewRecorder()
+
+		srv.Handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+		}
+		var resp CreateGameResponse
+		json.NewDecoder(w.Body).Decode(&resp)
+		return resp
+	}
+
+	host := create("Host")
+	if host.Status != "waiting" {
+		t.Fatalf("Expected the first player to wait, got %s", host.Status)
+	}
+
+	guest := create("Guest")
+	if guest.Status != "matched" || guest.RoomID != host.RoomID {
+		t.Errorf("Expected the second player to be matched into %s, got %s in %s", host.RoomID, guest.Status, guest.RoomID)
+	}
+}
+
+func TestHandleCreateGame_Solo(t *testing.T) {
+	mockStore := NewMockStore()
+	hub := socket.NewHub(&config.Config{}, mockStore)
+	srv := NewServer(&config.Config{}, hub, mockStore, matchmaking.NewService(&config.Config{}, mockStore, hub))
+
+	reqBody, _ := json.Marshal(CreateGameRequest{
+		PlayerName: "Solo",
+		Config: &store.GameConfig{
+			PinLength: 5,
+			Mode:      socket.GameModeSolo,
+		},
+	})
+
+	req := httptest.NewRequest("POST", "/games", bytes.NewBuffer(reqBody))
+	w := httptest.NewRecorder()
+
+	srv.Handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var resp CreateGameResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Status != "playing" {
+		t.Errorf("Expected status playing, got %s", resp.Status)
+	}
+
+	room, _ := mockStore.GetRoom(context.Background(), resp.RoomID)
+	if room.Status != "playing" || !room.RoundActive {
+		t.Errorf("Expected round 1 to be active, got status %s", room.Status)
+	}
+
+	// The server's player holds the generated pins
+	players, _ := mockStore.GetRoomPlayers(context.Background(), resp.RoomID)
+	if len(players) != 2 {
+		t.Fatalf("Expected 2 players, got %d", len(players))
+	}
+	for _, pid := range players {
+		if pid == resp.PlayerID {
+			continue
+		}
+		house, _ := mockStore.GetPlayer(context.Background(), pid)
+		if len(house.Pins) != 3 {
+			t.Fatalf("Expected 3 generated pins, got %d", len(house.Pins))
+		}
+		for _, pin := range house.Pins {
+			if err := socket.NewGameLogic().ValidateGuess(pin, 5); err != nil {
+				t.Errorf("Generated pin %q is invalid: %v", pin, err)
+			}
+		}
+	}
+}
+
+func TestHandleJoinGame_Concurrent(t *testing.T) {
+	mockStore := NewMockStore()
+	hub := socket.NewHub(&config.Config{}, mockStore)
+	srv := NewServer(&config.Config{}, hub, mockStore, matchmaking.NewService(&config.Config{}, mockStore, hub))
+
+	roomID := "room1"
+	mockStore.SaveRoom(nil, &store.Room{ID: roomID, HostID: "host", Status: "waiting", Config: &store.GameConfig{PinLength: 5}})
+	mockStore.SavePlayer(nil, &store.Player{ID: "host", RoomID: roomID})
+
+	const joiners = 20
+	codes := make(chan int, joiners)
+	var wg sync.WaitGroup
+	for i := 0; i < joiners; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			reqBody, _ := json.Marshal(JoinGameRequest{PlayerName: fmt.Sprintf("Player%d", i), RoomID: roomID})
+			req := httptest.NewRequest("POST", "/games/join", bytes.NewBuffer(reqBody))
+			w := httptest.NewRecorder()
+			srv.Handler.ServeHTTP(w, req)
+			codes <- w.Code
+		}(i)
+	}
+	wg.Wait()
+	close(codes)
+
+	joined, full := 0, 0
+	for code := range codes {
+		switch code {
+		case http.StatusOK:
+			joined++
+		case http.StatusConflict:
+			full++
+		default:
+			t.Errorf("Unexpected status %d", code)
+		}
+	}
+	if joined != 1 || full != joiners-1 {
+		t.Errorf("Expected 1 join and %d conflicts, got %d joins and %d conflicts", joiners-1, joined, full)
+	}
+
+	players, _ := mockStore.GetRoomPlayers(nil, roomID)
+	if len(players) != 2 {
+		t.Errorf("Expected 2 players in room, got %d", len(players))
+	}
+}
+
+func TestHandleJoinGame_Started(t *testing.T) {
+	mockStore := NewMockStore()
+	hub := socket.NewHub(&config.Config{}, mockStore)
+	srv := NewServer(&config.Config{}, hub, mockStore, matchmaking.NewService(&config.Config{}, mockStore, hub))
+
+	// The room has a free seat, but its game has already started
+	mockStore.SaveRoom(nil, &store.Room{ID: "room1", HostID: "host", Status: "playing", Config: &store.GameConfig{PinLength: 5}})
+	mockStore.SavePlayer(nil, &store.Player{ID: "host", RoomID: "room1"})
+
+	reqBody, _ := json.Marshal(JoinGameRequest{PlayerName: "Late", RoomID: "room1"})
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest("POST", "/games/join", bytes.NewBuffer(reqBody)))
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status %d, got %d. Body: %s", http.StatusConflict, w.Code, w.Body.String())
+	}
+	if players, _ := mockStore.GetRoomPlayers(nil, "room1"); len(players) != 1 {
+		t.Errorf("Expected only the host in the room, got %v", players)
+	}
 }
 
 func TestHandleSelectPin(t *testing.T) {
 	mockStore := NewMockStore()
 	hub := socket.NewHub(&config.Config{}, mockStore)
-	srv := NewServer(&config.Config{}, hub, mockStore)
+	srv := NewServer(&config.Config{}, hub, mockStore, matchmaking.NewService(&config.Config{}, mockStore, hub))
 
 	// Setup: Create Room and Player
 	roomID := "room1"
 	playerID := "player1"
 
 	room := &store.Room{
-		ID: roomID,
+		ID:     roomID,
+		Status: "waiting",
 		Config: &store.GameConfig{
 			PinLength: 5,
 		},
@@ -136,6 +364,7 @@ func TestHandleSelectPin(t *testing.T) {
 	req := httptest.NewRequest("POST", "/games/"+roomID+"/players/"+playerID+"/pin", bytes.NewBuffer(reqBody))
 	req.SetPathValue("gameID", roomID)
 	req.SetPathValue("playerID", playerID)
+	req.Header.Set("Authorization", "Bearer "+auth.NewSigner(nil).Sign(roomID, playerID))
 
 	w := httptest.NewRecorder()
 
@@ -156,10 +385,10 @@ func TestHandleSelectPin_GameStart(t *testing.T) {
 	mockStore := NewMockStore()
 	hub := socket.NewHub(&config.Config{}, mockStore)
 
-	// Start Hub to prevent blocking on Broadcast channel
+	// Start Hub to prevent blocking on the broadcast channel
 	go hub.Run()
 
-	srv := NewServer(&config.Config{}, hub, mockStore)
+	srv := NewServer(&config.Config{}, hub, mockStore, matchmaking.NewService(&config.Config{}, mockStore, hub))
 
 	// Setup: Create Room and 2 Players
 	roomID := "room_start_test"
@@ -181,6 +410,7 @@ func TestHandleSelectPin_GameStart(t *testing.T) {
 	req1 := httptest.NewRequest("POST", "/games/"+roomID+"/players/p1/pin", bytes.NewBuffer(body1))
 	req1.SetPathValue("gameID", roomID)
 	req1.SetPathValue("playerID", "p1")
+	req1.Header.Set("Authorization", "Bearer "+auth.NewSigner(nil).Sign(roomID, "p1"))
 	w1 := httptest.NewRecorder()
 	srv.Handler.ServeHTTP(w1, req1)
 
@@ -200,6 +430,7 @@ func TestHandleSelectPin_GameStart(t *testing.T) {
 	req2 := httptest.NewRequest("POST", "/games/"+roomID+"/players/p2/pin", bytes.NewBuffer(body2))
 	req2.SetPathValue("gameID", roomID)
 	req2.SetPathValue("playerID", "p2")
+	req2.Header.Set("Authorization", "Bearer "+auth.NewSigner(nil).Sign(roomID, "p2"))
 	w2 := httptest.NewRecorder()
 	srv.Handler.ServeHTTP(w2, req2)
 
@@ -212,4 +443,446 @@ func TestHandleSelectPin_GameStart(t *testing.T) {
 	if updatedRoom.Status != "playing" {
 		t.Errorf("Room status should be playing, got %s", updatedRoom.Status)
 	}
+	if !updatedRoom.RoundActive {
+		t.Error("Round 1 should be active after the game starts")
+	}
+}
+
+func TestHandleSelectPin_Authorization(t *testing.T) {
+	mockStore := NewMockStore()
+	hub := socket.NewHub(&config.Config{}, mockStore)
+	go hub.Run()
+	srv := NewServer(&config.Config{}, hub, mockStore, matchmaking.NewService(&config.Config{}, mockStore, hub))
+
+	mockStore.SaveRoom(context.Background(), &store.Room{ID: "waiting_room", Status: "waiting", Config: &store.GameConfig{PinLength: 4}})
+	mockStore.SavePlayer(context.Background(), &store.Player{ID: "p1", RoomID: "waiting_room"})
+	mockStore.SavePlayer(context.Background(), &store.Player{ID: "p2", RoomID: "waiting_room"})
+	mockStore.SaveRoom(context.Background(), &store.Room{ID: "started_room", Status: "playing", Config: &store.GameConfig{PinLength: 4}})
+	mockStore.SavePlayer(context.Background(), &store.Player{ID: "p3", RoomID: "started_room"})
+
+	signer := auth.NewSigner(nil)
+
+	tests := []struct {
+		name           string
+		roomID         string
+		playerID       string
+		token          string
+		expectedStatus int
+	}{
+		{name: "Missing Token", roomID: "waiting_room", playerID: "p1", expectedStatus: http.StatusUnauthorized},
+		{name: "Opponent's Token", roomID: "waiting_room", playerID: "p1", token: signer.Sign("waiting_room", "p2"), expectedStatus: http.StatusUnauthorized},
+		{name: "Owner's Token", roomID: "waiting_room", playerID: "p1", token: signer.Sign("waiting_room", "p1"), expectedStatus: http.StatusOK},
+		{name: "Pins Already Chosen", roomID: "waiting_room", playerID: "p1", token: signer.Sign("waiting_room", "p1"), expectedStatus: http.StatusConflict},
+		{name: "Game Started", roomID: "started_room", playerID: "p3", token: signer.Sign("started_room", "p3"), expectedStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(SelectPinRequest{Pins: []string{"0000", "0000", "0000"}})
+			req := httptest.NewRequest("POST", "/games/"+tt.roomID+"/players/"+tt.playerID+"/pin", bytes.NewBuffer(body))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+
+			srv.Handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d. Body: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleRandomPin(t *testing.T) {
+	mockStore := NewMockStore()
+	hub := socket.NewHub(&config.Config{}, mockStore)
+	go hub.Run()
+	srv := NewServer(&config.Config{}, hub, mockStore, matchmaking.NewService(&config.Config{}, mockStore, hub))
+
+	roomID := "room_random"
+	mockStore.SaveRoom(context.Background(), &store.Room{
+		ID:     roomID,
+		Status: "waiting",
+		Config: &store.GameConfig{PinLength: 5},
+	})
+	mockStore.SavePlayer(context.Background(), &store.Player{ID: "p1", RoomID: roomID})
+	mockStore.SavePlayer(context.Background(), &store.Player{ID: "p2", RoomID: roomID, Pins: []string{"11111", "22222", "33333"}})
+
+	signer := auth.NewSigner(nil)
+
+	tests := []struct {
+		name           string
+		token          string
+		expectedStatus int
+	}{
+		{name: "Missing Token", token: "", expectedStatus: http.StatusUnauthorized},
+		{name: "Other Player's Token", token: signer.Sign(roomID, "p2"), expectedStatus: http.StatusUnauthorized},
+		{name: "Owner's Token", token: signer.Sign(roomID, "p1"), expectedStatus: http.StatusOK},
+		{name: "Re-roll", token: signer.Sign(roomID, "p1"), expectedStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/games/"+roomID+"/players/p1/pin/random", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+
+			srv.Handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d. Body: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	player, _ := mockStore.GetPlayer(context.Background(), "p1")
+	if len(player.Pins) != 3 {
+		t.Fatalf("Expected 3 pins, got %d", len(player.Pins))
+	}
+	for _, pin := range player.Pins {
+		if len(pin) != 5 {
+			t.Errorf("Expected pin of length 5, got %q", pin)
+		}
+	}
+
+	// Both players now have pins, so the game starts
+	room, _ := mockStore.GetRoom(context.Background(), roomID)
+	if room.Status != "playing" {
+		t.Errorf("Room status should be playing, got %s", room.Status)
+	}
+
+	// Pins cannot be generated once the game is under way
+	mockStore.SaveRoom(context.Background(), &store.Room{ID: "room_started", Status: "playing", Config: &store.GameConfig{PinLength: 5}})
+	mockStore.SavePlayer(context.Background(), &store.Player{ID: "p3", RoomID: "room_started"})
+	req := httptest.NewRequest("POST", "/games/room_started/players/p3/pin/random", nil)
+	req.Header.Set("Authorization", "Bearer "+signer.Sign("room_started", "p3"))
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, req)
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 in a started game, got %d", w.Code)
+	}
+
+	// A token for this room cannot roll pins for a player who has since moved to another room
+	mockStore.SavePlayer(context.Background(), &store.Player{ID: "p4", RoomID: "room_other"})
+	req = httptest.NewRequest("POST", "/games/"+roomID+"/players/p4/pin/random", nil)
+	req.Header.Set("Authorization", "Bearer "+signer.Sign(roomID, "p4"))
+	w = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for a player of another room, got %d", w.Code)
+	}
+}
+
+func TestHandleSelectPin_RoundsConfig(t *testing.T) {
+	mockStore := NewMockStore()
+	hub := socket.NewHub(&config.Config{}, mockStore)
+	srv := NewServer(&config.Config{}, hub, mockStore, matchmaking.NewService(&config.Config{}, mockStore, hub))
+
+	roomID := "room_best_of_1"
+	mockStore.SaveRoom(context.Background(), &store.Room{
+		ID:     roomID,
+		Status: "waiting",
+		Config: &store.GameConfig{PinLength: 4, Rounds: 1},
+	})
+	mockStore.SavePlayer(context.Background(), &store.Player{ID: "p1", RoomID: roomID})
+
+	tests := []struct {
+		name           string
+		pins           []string
+		expectedStatus int
+	}{
+		{name: "Default Three Pins", pins: []string{"1111", "2222", "3333"}, expectedStatus: http.StatusBadRequest},
+		{name: "One Pin Per Round", pins: []string{"1111"}, expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(SelectPinRequest{Pins: tt.pins})
+			req := httptest.NewRequest("POST", "/games/"+roomID+"/players/p1/pin", bytes.NewBuffer(body))
+			req.Header.Set("Authorization", "Bearer "+auth.NewSigner(nil).Sign(roomID, "p1"))
+			w := httptest.NewRecorder()
+
+			srv.Handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d. Body: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleListGuesses(t *testing.T) {
+	mockStore := NewMockStore()
+	hub := socket.NewHub(&config.Config{}, mockStore)
+	srv := NewServer(&config.Config{}, hub, mockStore, matchmaking.NewService(&config.Config{}, mockStore, hub))
+
+	roomID := "room_history"
+	mockStore.SaveRoom(context.Background(), &store.Room{
+		ID:     roomID,
+		Config: &store.GameConfig{PinLength: 4},
+	})
+	mockStore.AppendGuess(context.Background(), roomID, 1, &store.GuessRecord{PlayerID: "p1", Guess: "1234", Hints: []int{2, 0, 0, 1}})
+	mockStore.AppendGuess(context.Background(), roomID, 1, &store.GuessRecord{PlayerID: "p2", Guess: "5678", Hints: []int{0, 0, 0, 0}})
+
+	tests := []struct {
+		name           string
+		round          string
+		expectedStatus int
+		expectedCount  int
+	}{
+		{name: "Round With Guesses", round: "1", expectedStatus: http.StatusOK, expectedCount: 2},
+		{name: "Round Without Guesses", round: "2", expectedStatus: http.StatusOK, expectedCount: 0},
+		{name: "Round Out Of Range", round: "4", expectedStatus: http.StatusBadRequest},
+		{name: "Round Not A Number", round: "first", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/games/"+roomID+"/rounds/"+tt.round+"/guesses", nil)
+			w := httptest.NewRecorder()
+
+			srv.Handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Body: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			var resp ListGuessesResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if len(resp.Guesses) != tt.expectedCount {
+				t.Errorf("Expected %d guesses, got %d", tt.expectedCount, len(resp.Guesses))
+			}
+		})
+	}
+}
+
+func TestHandleGetGame_HidesPins(t *testing.T) {
+	mockStore := NewMockStore()
+	hub := socket.NewHub(&config.Config{}, mockStore)
+	srv := NewServer(&config.Config{}, hub, mockStore, matchmaking.NewService(&config.Config{}, mockStore, hub))
+
+	roomID := "room_view"
+	mockStore.SaveRoom(context.Background(), &store.Room{
+		ID:           roomID,
+		HostID:       "p1",
+		Status:       "playing",
+		Config:       &store.GameConfig{PinLength: 4},
+		CurrentRound: 2,
+		RoundActive:  true,
+	})
+	mockStore.SavePlayer(context.Background(), &store.Player{ID: "p1", Name: "Host", RoomID: roomID, Pins: []string{"1111", "2222", "3333"}})
+	mockStore.SavePlayer(context.Background(), &store.Player{ID: "p2", Name: "Guest", RoomID: roomID, Pins: []string{"4444", "5555", "6666"}})
+
+	signer := auth.NewSigner(nil)
+
+	tests := []struct {
+		name           string
+		token          string
+		expectedStatus int
+		expectedPins   []string // Own pins, or nil for a public view
+		hiddenPins     []string
+	}{
+		{name: "Anonymous", expectedStatus: http.StatusOK, hiddenPins: []string{"2222", "3333", "5555", "6666"}},
+		{name: "Host", token: signer.Sign(roomID, "p1"), expectedStatus: http.StatusOK, expectedPins: []string{"1111", "2222", "3333"}, hiddenPins: []string{"5555", "6666"}},
+		{name: "Guest", token: signer.Sign(roomID, "p2"), expectedStatus: http.StatusOK, expectedPins: []string{"4444", "5555", "6666"}, hiddenPins: []string{"2222", "3333"}},
+		{name: "Other Room's Token", token: signer.Sign("other_room", "p1"), expectedStatus: http.StatusUnauthorized},
+		{name: "Invalid Token", token: "forged", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/games/"+roomID, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+
+			srv.Handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Body: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			for _, pin := range tt.hiddenPins {
+				if strings.Contains(w.Body.String(), pin) {
+					t.Errorf("Pin %s should be hidden, got %s", pin, w.Body.String())
+				}
+			}
+
+			var resp PrivateRoom
+			json.NewDecoder(w.Body).Decode(&resp)
+
+			// Round 1 has finished, so its pins are public
+			if len(resp.Players) != 2 || resp.Players[0].ID != "p1" {
+				t.Fatalf("Expected host first of 2 players, got %+v", resp.Players)
+			}
+			for i, want := range []string{"1111", "4444"} {
+				if got := resp.Players[i].RevealedPins; len(got) != 1 || got[0] != want {
+					t.Errorf("Expected revealed pins [%s], got %v", want, got)
+				}
+			}
+
+			if tt.expectedPins == nil {
+				if resp.Player != nil {
+					t.Errorf("Expected no private player, got %+v", resp.Player)
+				}
+			} else if resp.Player == nil || !reflect.DeepEqual(resp.Player.Pins, tt.expectedPins) {
+				t.Errorf("Expected own pins %v, got %+v", tt.expectedPins, resp.Player)
+			}
+		})
+	}
+}
+
+func TestHandleLeaveMatchmaking(t *testing.T) {
+	mockStore := NewMockStore()
+	hub := socket.NewHub(&config.Config{}, mockStore)
+	srv := NewServer(&config.Config{}, hub, mockStore, matchmaking.NewService(&config.Config{}, mockStore, hub))
+
+	reqBody, _ := json.Marshal(CreateGameRequest{
+		PlayerName: "Host",
+		Config:     &store.GameConfig{PinLength: 5, TimerDuration: 30},
+	})
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest("POST", "/games", bytes.NewBuffer(reqBody)))
+	var created CreateGameResponse
+	json.NewDecoder(w.Body).Decode(&created)
+
+	stats := func() matchmaking.QueueStats {
+		w := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/matchmaking/stats", nil))
+		var resp matchmaking.QueueStats
+		json.NewDecoder(w.Body).Decode(&resp)
+		return resp
+	}
+
+	if got := stats(); got.Waiting != 1 || len(got.Buckets) != 1 || got.Buckets[0].Key != (&store.GameConfig{PinLength: 5, TimerDuration: 30}).MatchKey() {
+		t.Fatalf("Expected one waiting player, got %+v", got)
+	}
+
+	signer := auth.NewSigner(nil)
+
+	tests := []struct {
+		name           string
+		token          string
+		expectedStatus int
+	}{
+		{name: "Missing Token", token: "", expectedStatus: http.StatusUnauthorized},
+		{name: "Other Room's Token", token: signer.Sign("other_room", created.PlayerID), expectedStatus: http.StatusUnauthorized},
+		{name: "Host's Token", token: created.Token, expectedStatus: http.StatusOK},
+		{name: "Already Left", token: created.Token, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("DELETE", "/games/"+created.RoomID+"/matchmaking", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+
+			srv.Handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d. Body: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	if got := stats(); got.Waiting != 0 || len(got.Buckets) != 0 {
+		t.Errorf("Expected nobody waiting, got %+v", got)
+	}
+}
+
+func TestHandleProfiles(t *testing.T) {
+	mockStore := NewMockStore()
+	hub := socket.NewHub(&config.Config{}, mockStore)
+	go hub.Run()
+	srv := NewServer(&config.Config{}, hub, mockStore, matchmaking.NewService(&config.Config{}, mockStore, hub))
+
+	post := func(path string, body interface{}) *httptest.ResponseRecorder {
+		reqBody, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(w, httptest.NewRequest("POST", path, bytes.NewBuffer(reqBody)))
+		return w
+	}
+
+	// Players without a profile get a new one, which is not stored until it finishes a rated game
+	w := post("/games", CreateGameRequest{PlayerName: "Host", Config: &store.GameConfig{PinLength: 5, IsPrivate: true}})
+	var created CreateGameResponse
+	json.NewDecoder(w.Body).Decode(&created)
+	if created.ProfileID == "" || created.ProfileToken == "" {
+		t.Fatal("Expected a profile to be created for the host")
+	}
+
+	w = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/profiles/"+created.ProfileID, nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected the new profile not to be stored yet, got %d", w.Code)
+	}
+
+	// Sending the profile token back plays as it again
+	w = post("/games/join", JoinGameRequest{PlayerName: "Host Again", RoomID: created.RoomID, ProfileToken: created.ProfileToken})
+	var joined JoinGameResponse
+	json.NewDecoder(w.Body).Decode(&joined)
+	if joined.ProfileID != created.ProfileID {
+		t.Errorf("Expected to join as profile %s, got %s", created.ProfileID, joined.ProfileID)
+	}
+	if player, _ := mockStore.GetPlayer(nil, joined.PlayerID); player.ProfileID != created.ProfileID {
+		t.Errorf("Expected the player to be linked to profile %s, got %s", created.ProfileID, player.ProfileID)
+	}
+
+	// Requests that fail store no profiles either
+	post("/games", CreateGameRequest{PlayerName: "Solo", Config: &store.GameConfig{Mode: socket.GameModeSolo}})
+	post("/games/join", JoinGameRequest{PlayerName: "Late", RoomID: created.RoomID})
+	if len(mockStore.profiles) != 0 {
+		t.Errorf("Expected no stored profiles, got %d", len(mockStore.profiles))
+	}
+
+	// Matchmaking compares profile ratings
+	mockStore.SaveProfile(nil, &store.Profile{ID: "strong", Rating: 1900, Deviation: 50, Volatility: 0.06})
+	post("/games", CreateGameRequest{PlayerName: "Strong", ProfileToken: auth.NewSigner(nil).SignProfile("strong"), RatingBand: 100, Config: &store.GameConfig{PinLength: 4}})
+	w = post("/games", CreateGameRequest{PlayerName: "New", Config: &store.GameConfig{PinLength: 4}})
+	var unmatched CreateGameResponse
+	json.NewDecoder(w.Body).Decode(&unmatched)
+	if unmatched.Status != "waiting" {
+		t.Errorf("Expected a new player not to be matched with a 1900 rated host, got %s", unmatched.Status)
+	}
+
+	tests := []struct {
+		name           string
+		w              func() *httptest.ResponseRecorder
+		expectedStatus int
+	}{
+		{name: "Get Unknown Profile", expectedStatus: http.StatusNotFound, w: func() *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			srv.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/profiles/missing", nil))
+			return w
+		}},
+		// The public profile ID is not a credential
+		{name: "Create With Profile ID", expectedStatus: http.StatusUnauthorized, w: func() *httptest.ResponseRecorder {
+			return post("/games", CreateGameRequest{PlayerName: "Host", ProfileToken: created.ProfileID, Config: &store.GameConfig{PinLength: 5}})
+		}},
+		{name: "Join With Session Token", expectedStatus: http.StatusUnauthorized, w: func() *httptest.ResponseRecorder {
+			return post("/games/join", JoinGameRequest{PlayerName: "Guest", RoomID: created.RoomID, ProfileToken: created.Token})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := tt.w(); w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
 }
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/obasekietinosa/lockpick-api/internal/socket"
+	"github.com/obasekietinosa/lockpick-api/internal/store"
+)
+
+// @Summary Get a player profile
+// @Description Rating and record of a player across all their rated games
+// @Tags profiles
+// @Produce json
+// @Param profileID path string true "Profile ID"
+// @Success 200 {object} store.Profile
+// @Router /profiles/{profileID} [get]
+func (s *Server) HandleGetProfile(w http.ResponseWriter, r *http.Request) {
+	profile, err := s.store.GetProfile(r.Context(), r.PathValue("profileID"))
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profile)
+}
+
+// playerProfile returns the profile a new player plays as. Players who do not send a profile token
+// get a new profile with the initial rating, whose token they can send again to carry their rating to later games.
+// Profiles are only stored once they finish a rated game, so requests that fail or games that are never rated leave nothing behind.
+// The profile ID alone is public, so it is never enough to play as a profile.
+func (s *Server) playerProfile(ctx context.Context, profileToken, name string) (*store.Profile, error) {
+	if profileToken == "" {
+		return socket.NewProfile(uuid.New().String(), name), nil
+	}
+
+	profileID, err := s.sessions.VerifyProfile(profileToken)
+	if err != nil {
+		return nil, err
+	}
+	return socket.LoadProfile(ctx, s.store, profileID, name)
+}
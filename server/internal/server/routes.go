@@ -12,11 +12,17 @@ func (s *Server) RegisterRoutes() http.Handler {
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/health", s.healthHandler)
+	mux.HandleFunc("GET /metrics/sweeper", s.sweeperMetricsHandler)
+	mux.HandleFunc("GET /matchmaking/stats", s.HandleMatchmakingStats)
 	mux.HandleFunc("/ws", s.socketHandler)
 	mux.HandleFunc("POST /games", s.HandleCreateGame)
 	mux.HandleFunc("POST /games/join", s.HandleJoinGame)
 	mux.HandleFunc("POST /games/{gameID}/players/{playerID}/pin", s.HandleSelectPin)
+	mux.HandleFunc("POST /games/{gameID}/players/{playerID}/pin/random", s.HandleRandomPin)
 	mux.HandleFunc("GET /games/{gameID}", s.HandleGetGame)
+	mux.HandleFunc("DELETE /games/{gameID}/matchmaking", s.HandleLeaveMatchmaking)
+	mux.HandleFunc("GET /games/{gameID}/rounds/{round}/guesses", s.HandleListGuesses)
+	mux.HandleFunc("GET /profiles/{profileID}", s.HandleGetProfile)
 
 	// Swagger Handler
 	mux.HandleFunc("/swagger/", httpSwagger.WrapHandler)
@@ -42,3 +48,14 @@ func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write(jsonResp)
 }
+
+// @Summary Sweeper metrics
+// @Description Totals of matchmaking entries and timers reclaimed by the background sweeper since startup
+// @Tags health
+// @Produce json
+// @Success 200 {object} socket.SweepStats
+// @Router /metrics/sweeper [get]
+func (s *Server) sweeperMetricsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.hub.SweepStats())
+}
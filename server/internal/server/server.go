@@ -5,22 +5,30 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/obasekietinosa/lockpick-api/internal/auth"
 	"github.com/obasekietinosa/lockpick-api/internal/config"
+	"github.com/obasekietinosa/lockpick-api/internal/matchmaking"
 	"github.com/obasekietinosa/lockpick-api/internal/socket"
 	"github.com/obasekietinosa/lockpick-api/internal/store"
 )
 
 type Server struct {
-	port  string
-	hub   *socket.Hub
-	store store.Store
+	port       string
+	hub        *socket.Hub
+	store      store.Store
+	sessions   *auth.Signer
+	gameLogic  *socket.GameLogic
+	matchmaker *matchmaking.Service
 }
 
-func NewServer(cfg *config.Config, hub *socket.Hub, store store.Store) *http.Server {
+func NewServer(cfg *config.Config, hub *socket.Hub, store store.Store, matchmaker *matchmaking.Service) *http.Server {
 	NewServer := &Server{
-		port:  cfg.Port,
-		hub:   hub,
-		store: store,
+		port:       cfg.Port,
+		hub:        hub,
+		store:      store,
+		sessions:   auth.NewSigner([]byte(cfg.SessionSecret)),
+		gameLogic:  socket.NewGameLogic(),
+		matchmaker: matchmaker,
 	}
 
 	// Declare Server config
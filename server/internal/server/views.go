@@ -0,0 +1,87 @@
+package server
+
+import (
+	"time"
+
+	"github.com/obasekietinosa/lockpick-api/internal/socket"
+	"github.com/obasekietinosa/lockpick-api/internal/store"
+)
+
+// PublicPlayer is what anybody can see of a player. Pins are only included once their round has finished.
+type PublicPlayer struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	RevealedPins []string `json:"revealed_pins"` // Pins of finished rounds, in round order
+}
+
+// PrivatePlayer is what a player can see of themselves, including pins their opponent has not guessed yet
+type PrivatePlayer struct {
+	PublicPlayer
+	Pins []string `json:"pins"`
+}
+
+// PublicRoom is what anybody can see of a game
+type PublicRoom struct {
+	ID             string                        `json:"id"`
+	HostID         string                        `json:"host_id"`
+	Status         string                        `json:"status"`
+	Config         *store.GameConfig             `json:"config"`
+	CurrentRound   int                           `json:"current_round"`
+	Scores         map[string]int                `json:"scores"`
+	CreatedAt      time.Time                     `json:"created_at"`
+	ReadyPlayers   []string                      `json:"ready_players"`
+	RoundActive    bool                          `json:"round_active"`
+	CurrentTurn    string                        `json:"current_turn,omitempty"`
+	RoundStartedAt time.Time                     `json:"round_started_at"`
+	RoundStats     map[string]*store.PlayerStats `json:"round_stats,omitempty"`
+	GameStats      map[string]*store.PlayerStats `json:"game_stats,omitempty"`
+	Players        []PublicPlayer                `json:"players"` // Host first
+}
+
+// PrivateRoom is a game as seen by one of its players
+type PrivateRoom struct {
+	PublicRoom
+	Player *PrivatePlayer `json:"player"`
+}
+
+func newPublicPlayer(room *store.Room, player *store.Player) PublicPlayer {
+	return PublicPlayer{
+		ID:           player.ID,
+		Name:         player.Name,
+		RevealedPins: socket.RevealedPins(room, player.Pins),
+	}
+}
+
+func newPrivatePlayer(room *store.Room, player *store.Player) *PrivatePlayer {
+	pins := player.Pins
+	if pins == nil {
+		pins = []string{}
+	}
+	return &PrivatePlayer{
+		PublicPlayer: newPublicPlayer(room, player),
+		Pins:         pins,
+	}
+}
+
+func newPublicRoom(room *store.Room, players []*store.Player) PublicRoom {
+	view := PublicRoom{
+		ID:             room.ID,
+		HostID:         room.HostID,
+		Status:         room.Status,
+		Config:         room.Config,
+		CurrentRound:   room.CurrentRound,
+		Scores:         room.Scores,
+		CreatedAt:      room.CreatedAt,
+		ReadyPlayers:   room.ReadyPlayers,
+		RoundActive:    room.RoundActive,
+		CurrentTurn:    room.CurrentTurn,
+		RoundStartedAt: room.RoundStartedAt,
+		RoundStats:     room.RoundStats,
+		GameStats:      room.GameStats,
+		Players:        make([]PublicPlayer, 0, len(players)),
+	}
+	for _, p := range players {
+		view.Players = append(view.Players, newPublicPlayer(room, p))
+	}
+	return view
+}
@@ -40,6 +40,12 @@ type Client struct {
 
 	// Buffered channel of outbound messages.
 	Send chan []byte
+
+	// The room this client is subscribed to. Only messages for this room are delivered.
+	RoomID string
+
+	// The player this connection belongs to.
+	PlayerID string
 }
 
 // readPump pumps messages from the websocket connection to the hub.
@@ -65,28 +71,7 @@ func (c *Client) readPump() {
 			break
 		}
 
-		// Handle different message types
-		switch msg.Type {
-		case "guess":
-			// We need to handle the payload as a map string interface because ReadJSON unmarshals interface{}
-			// Or we can re-marshal and unmarshal, or use mapstructure.
-			// For simplicity/performance in this MVP, let's manually cast or let the Hub handle it.
-			// Actually, let's pass the raw message to the Hub to route/process to avoid complex logic here.
-			// But wait, the Hub right now just broadcasts bytes.
-			// We need to upgrade the Hub or handle it here.
-
-			// Let's forward the parsed message to the Hub via a new channel or wrapper?
-			// The current Hub.Broadcast takes []byte.
-			// Let's re-serialize appropriately or change Hub.Broadcast.
-			// For now, to keep `client.go` simple, let's process the logic here or call a handler.
-
-			// Re-serializing for broadcast is temporary behavior.
-			// We really want to invoke Game Logic.
-			c.Hub.HandleMessage(c, msg)
-
-		default:
-			log.Printf("Unknown message type: %s", msg.Type)
-		}
+		c.Hub.HandleMessage(c, msg)
 	}
 }
 
@@ -136,13 +121,27 @@ func (c *Client) writePump() {
 }
 
 // ServeWs handles websocket requests from the peer.
+// The token query parameter must be a session token issued by the HTTP API;
+// it pins the connection to the player and room it was issued for.
 func ServeWs(hub *Hub, w http.ResponseWriter, r *http.Request) {
+	session, err := hub.sessions.Verify(r.URL.Query().Get("token"))
+	if err != nil {
+		http.Error(w, "Invalid session token", http.StatusUnauthorized)
+		return
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Println(err)
 		return
 	}
-	client := &Client{Hub: hub, Conn: conn, Send: make(chan []byte, 256)}
+	client := &Client{
+		Hub:      hub,
+		Conn:     conn,
+		Send:     make(chan []byte, 256),
+		RoomID:   session.RoomID,
+		PlayerID: session.PlayerID,
+	}
 	client.Hub.Register <- client
 
 	// Allow collection of memory referenced by the caller by doing all work in
@@ -0,0 +1,170 @@
+package socket
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/obasekietinosa/lockpick-api/internal/store"
+)
+
+func graceKey(roomID, playerID string) string {
+	return roomID + ":" + playerID
+}
+
+// OnDisconnect registers fn to be called whenever a player loses their last connection to a room.
+// It must be called before Run starts. fn runs on its own goroutine, so it may use the Hub.
+func (h *Hub) OnDisconnect(fn func(roomID, playerID string)) {
+	h.disconnectHandlers = append(h.disconnectHandlers, fn)
+}
+
+// notifyDisconnect runs the handlers registered with OnDisconnect.
+// It must only be called from the Run goroutine.
+func (h *Hub) notifyDisconnect(roomID, playerID string) {
+	for _, fn := range h.disconnectHandlers {
+		go fn(roomID, playerID)
+	}
+}
+
+// startGraceTimer gives a player who lost their last connection time to reconnect.
+// It must only be called from the Run goroutine.
+func (h *Hub) startGraceTimer(roomID, playerID string) {
+	if playerID == "" {
+		return
+	}
+
+	h.graceMu.Lock()
+	defer h.graceMu.Unlock()
+
+	key := graceKey(roomID, playerID)
+	if cancel, ok := h.graceTimers[key]; ok {
+		cancel()
+	}
+
+	timerCtx, cancel := context.WithCancel(context.Background())
+	h.graceTimers[key] = cancel
+
+	go h.awaitReconnect(timerCtx, roomID, playerID)
+}
+
+// cancelGraceTimer stops the grace timer of a player who reconnected and tells the room they are back.
+// It must only be called from the Run goroutine.
+func (h *Hub) cancelGraceTimer(roomID, playerID string) {
+	h.graceMu.Lock()
+	defer h.graceMu.Unlock()
+
+	key := graceKey(roomID, playerID)
+	cancel, ok := h.graceTimers[key]
+	if !ok {
+		return
+	}
+	cancel()
+	delete(h.graceTimers, key)
+
+	// Run cannot broadcast to itself
+	go h.BroadcastToRoom(roomID, GameMessage{
+		Type: "opponent_reconnected",
+		Payload: map[string]interface{}{
+			"room_id":   roomID,
+			"player_id": playerID,
+		},
+	})
+}
+
+// releaseGraceTimer removes the grace timer owning timerCtx. It returns false if the timer
+// was cancelled or replaced in the meantime.
+func (h *Hub) releaseGraceTimer(timerCtx context.Context, roomID, playerID string) bool {
+	h.graceMu.Lock()
+	defer h.graceMu.Unlock()
+
+	if timerCtx.Err() != nil {
+		return false
+	}
+	key := graceKey(roomID, playerID)
+	h.graceTimers[key]()
+	delete(h.graceTimers, key)
+	return true
+}
+
+func (h *Hub) awaitReconnect(timerCtx context.Context, roomID, playerID string) {
+	room, err := h.store.GetRoom(context.Background(), roomID)
+	if err != nil {
+		log.Printf("Error getting room for disconnect: %v", err)
+		h.releaseGraceTimer(timerCtx, roomID, playerID)
+		return
+	}
+
+	// Only games in progress can be forfeited. Nobody is waiting on the player in solo games.
+	if !hasStarted(room) || IsSolo(room.Config) {
+		h.releaseGraceTimer(timerCtx, roomID, playerID)
+		return
+	}
+
+	if timerCtx.Err() != nil {
+		return
+	}
+
+	log.Printf("Player %s disconnected from room %s", playerID, roomID)
+	h.BroadcastToRoom(roomID, GameMessage{
+		Type: "opponent_disconnected",
+		Payload: map[string]interface{}{
+			"room_id":      roomID,
+			"player_id":    playerID,
+			"grace_period": int(h.disconnectGrace.Seconds()),
+		},
+	})
+
+	select {
+	case <-time.After(h.disconnectGrace):
+		if h.releaseGraceTimer(timerCtx, roomID, playerID) {
+			h.handleForfeit(roomID, playerID)
+		}
+	case <-timerCtx.Done():
+		// Player reconnected
+		return
+	}
+}
+
+// hasStarted reports whether the room has a game in progress whose first round has been opened.
+// Players who leave while still choosing pins have nothing to forfeit, whatever the room's status.
+func hasStarted(room *store.Room) bool {
+	return room.Status == "playing" && (room.RoundActive || !room.RoundStartedAt.IsZero())
+}
+
+// handleForfeit ends the game in favour of the opponent of a player who did not reconnect in time
+func (h *Hub) handleForfeit(roomID, playerID string) {
+	err := store.RetryOnConflict(func() error {
+		ctx := context.Background()
+		room, err := h.store.GetRoom(ctx, roomID)
+		if err != nil {
+			log.Printf("Error getting room for forfeit: %v", err)
+			return nil
+		}
+
+		// The game may have ended while the player was away
+		if !hasStarted(room) {
+			return nil
+		}
+
+		opponentID, err := h.opponentID(ctx, room, playerID)
+		if err != nil {
+			log.Printf("Error getting opponent for forfeit: %v", err)
+			return nil
+		}
+
+		room.RoundActive = false
+		room.CurrentTurn = ""
+		announce := h.closeGame(room, opponentID, false, "", GameEndForfeit)
+		if err := h.store.SaveRoom(ctx, room); err != nil {
+			return err
+		}
+
+		log.Printf("Player %s forfeited room %s", playerID, roomID)
+		h.stopRoomTimers(room.ID)
+		announce()
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error forfeiting room %s: %v", roomID, err)
+	}
+}
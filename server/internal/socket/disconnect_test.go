@@ -0,0 +1,169 @@
+package socket
+
+import (
+	"testing"
+	"time"
+
+	"github.com/obasekietinosa/lockpick-api/internal/config"
+	"github.com/obasekietinosa/lockpick-api/internal/store"
+)
+
+// newDisconnectHub returns a running hub with a short grace period and two connected players in a game
+func newDisconnectHub(t *testing.T) (*Hub, *store.MemoryStore, *Client, *Client) {
+	t.Helper()
+	memStore := store.NewMemoryStore()
+	hub := NewHub(&config.Config{}, memStore)
+	hub.disconnectGrace = 200 * time.Millisecond
+	go hub.Run()
+
+	roomID := "room1"
+	memStore.SaveRoom(nil, &store.Room{
+		ID:           roomID,
+		HostID:       "p1",
+		Status:       "playing",
+		Config:       &store.GameConfig{PinLength: 5},
+		CurrentRound: 1,
+		RoundActive:  true,
+		Scores:       map[string]int{"p1": 0, "p2": 1},
+	})
+	addPlayer(memStore, &store.Player{ID: "p1", RoomID: roomID})
+	addPlayer(memStore, &store.Player{ID: "p2", RoomID: roomID})
+
+	client1 := &Client{Hub: hub, Send: make(chan []byte, 10), RoomID: roomID, PlayerID: "p1"}
+	client2 := &Client{Hub: hub, Send: make(chan []byte, 10), RoomID: roomID, PlayerID: "p2"}
+	hub.Register <- client1
+	hub.Register <- client2
+
+	return hub, memStore, client1, client2
+}
+
+func TestHub_Disconnect_Forfeit(t *testing.T) {
+	hub, memStore, client1, client2 := newDisconnectHub(t)
+
+	hub.Unregister <- client2
+
+	msg := readMessage(t, client1)
+	if msg.Type != "opponent_disconnected" {
+		t.Fatalf("Expected opponent_disconnected, got %s", msg.Type)
+	}
+	if msg.Payload.(map[string]interface{})["player_id"] != "p2" {
+		t.Errorf("Expected disconnected player p2, got %v", msg.Payload)
+	}
+
+	msg = readMessage(t, client1)
+	if msg.Type != "game_end" {
+		t.Fatalf("Expected game_end, got %s", msg.Type)
+	}
+	payload := msg.Payload.(map[string]interface{})
+	if payload["reason"] != GameEndForfeit {
+		t.Errorf("Expected reason %s, got %v", GameEndForfeit, payload["reason"])
+	}
+	if payload["winner_id"] != "p1" {
+		t.Errorf("Expected p1 to win by forfeit, got %v", payload["winner_id"])
+	}
+
+	if room, _ := memStore.GetRoom(nil, "room1"); room.Status != "finished" || room.RoundActive {
+		t.Errorf("Expected finished room with no active round, got status %s, round active %v", room.Status, room.RoundActive)
+	}
+}
+
+func TestHub_Disconnect_Reconnect(t *testing.T) {
+	hub, memStore, client1, client2 := newDisconnectHub(t)
+
+	hub.Unregister <- client2
+
+	msg := readMessage(t, client1)
+	if msg.Type != "opponent_disconnected" {
+		t.Fatalf("Expected opponent_disconnected, got %s", msg.Type)
+	}
+
+	reconnected := &Client{Hub: hub, Send: make(chan []byte, 10), RoomID: client2.RoomID, PlayerID: "p2"}
+	hub.Register <- reconnected
+
+	msg = readMessage(t, client1)
+	if msg.Type != "opponent_reconnected" {
+		t.Fatalf("Expected opponent_reconnected, got %s", msg.Type)
+	}
+
+	// Outlast the grace period
+	time.Sleep(2 * hub.disconnectGrace)
+	select {
+	case data := <-client1.Send:
+		t.Fatalf("Expected no further messages, got %s", data)
+	default:
+	}
+
+	if room, _ := memStore.GetRoom(nil, "room1"); room.Status != "playing" {
+		t.Errorf("Expected game to continue, got status %s", room.Status)
+	}
+}
+
+func TestHub_Disconnect_OtherConnectionRemains(t *testing.T) {
+	hub, _, client1, client2 := newDisconnectHub(t)
+
+	secondTab := &Client{Hub: hub, Send: make(chan []byte, 10), RoomID: client2.RoomID, PlayerID: "p2"}
+	hub.Register <- secondTab
+	hub.Unregister <- client2
+
+	time.Sleep(2 * hub.disconnectGrace)
+	select {
+	case data := <-client1.Send:
+		t.Fatalf("Expected no messages while the player is still connected, got %s", data)
+	default:
+	}
+}
+
+func TestHub_Disconnect_BeforeFirstRound(t *testing.T) {
+	hub, memStore, client1, client2 := newDisconnectHub(t)
+
+	// A room that is playing but still waiting for pins, as matched rooms used to be
+	room, _ := memStore.GetRoom(nil, "room1")
+	room.RoundActive = false
+	room.RoundStartedAt = time.Time{}
+	memStore.SaveRoom(nil, room)
+
+	hub.Unregister <- client2
+
+	time.Sleep(2 * hub.disconnectGrace)
+	select {
+	case data := <-client1.Send:
+		t.Fatalf("Expected no forfeit before the first round, got %s", data)
+	default:
+	}
+
+	if room, _ := memStore.GetRoom(nil, "room1"); room.Status != "playing" {
+		t.Errorf("Expected the room to be left alone, got status %s", room.Status)
+	}
+}
+
+func TestHub_OnDisconnect(t *testing.T) {
+	hub := NewHub(&config.Config{}, store.NewMemoryStore())
+	disconnected := make(chan string, 1)
+	hub.OnDisconnect(func(roomID, playerID string) {
+		disconnected <- roomID + ":" + playerID
+	})
+	go hub.Run()
+
+	first := &Client{Hub: hub, Send: make(chan []byte, 10), RoomID: "room1", PlayerID: "p1"}
+	second := &Client{Hub: hub, Send: make(chan []byte, 10), RoomID: "room1", PlayerID: "p1"}
+	hub.Register <- first
+	hub.Register <- second
+
+	// The player is still connected through their other connection
+	hub.Unregister <- first
+	select {
+	case got := <-disconnected:
+		t.Fatalf("Expected no disconnect while a connection remains, got %s", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	hub.Unregister <- second
+	select {
+	case got := <-disconnected:
+		if got != "room1:p1" {
+			t.Errorf("Expected room1:p1 to disconnect, got %s", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for disconnect")
+	}
+}
@@ -1,7 +1,13 @@
 package socket
 
 import (
+	"crypto/rand"
+	"fmt"
 	"log"
+	"math/big"
+	"time"
+
+	"github.com/obasekietinosa/lockpick-api/internal/store"
 )
 
 // GameMessage represents the structure of messages sent over the websocket
@@ -23,6 +29,72 @@ type PlayerReadyPayload struct {
 	PlayerID string `json:"player_id"`
 }
 
+// ResumePayload represents the payload for a resume message sent after reconnecting
+type ResumePayload struct {
+	RoomID   string `json:"room_id"`
+	PlayerID string `json:"player_id"`
+}
+
+// Game modes selectable through GameConfig.Mode
+const (
+	GameModeVersus = "versus" // Default: two players guess each other's pins
+	GameModeSolo   = "solo"   // A single player guesses pins generated by the server
+)
+
+// IsValidGameMode reports whether the mode is supported. An empty mode selects versus.
+func IsValidGameMode(mode string) bool {
+	return mode == "" || mode == GameModeVersus || mode == GameModeSolo
+}
+
+// IsSolo reports whether the game is played against server-generated pins
+func IsSolo(config *store.GameConfig) bool {
+	return config != nil && config.Mode == GameModeSolo
+}
+
+// RevealedPins returns the pins for the rounds of the room that have finished. They are no longer secret,
+// unlike the pins for the current round and those still to be played.
+func RevealedPins(room *store.Room, pins []string) []string {
+	finished := room.CurrentRound - 1
+	if room.Status == "finished" {
+		finished = room.CurrentRound
+	}
+	finished = max(0, min(finished, len(pins)))
+	return append([]string{}, pins[:finished]...)
+}
+
+// OpenRound opens the room's current round for guesses and starts the clock for its stats.
+// It belongs in the same save that opens the round, so no guess can be counted before its stats are reset.
+func OpenRound(room *store.Room, now time.Time) {
+	room.RoundActive = true
+	room.RoundStartedAt = now
+	room.RoundStats = make(map[string]*store.PlayerStats)
+}
+
+// ErrorCode identifies the kind of error reported to a client
+type ErrorCode string
+
+// Error codes are part of the client protocol and must not change once released
+const (
+	ErrCodeUnknownMessageType ErrorCode = "unknown_message_type"
+	ErrCodeInvalidPayload     ErrorCode = "invalid_payload"
+	ErrCodeUnauthorized       ErrorCode = "unauthorized"
+	ErrCodeRoomNotFound       ErrorCode = "room_not_found"
+	ErrCodeNotYourTurn        ErrorCode = "not_your_turn"
+	ErrCodeInvalidGuess       ErrorCode = "invalid_guess"
+	ErrCodeRoundNotActive     ErrorCode = "round_not_active"
+	ErrCodeRoundInProgress    ErrorCode = "round_in_progress"
+	ErrCodeGameFinished       ErrorCode = "game_finished"
+	ErrCodeNoGuessesLeft      ErrorCode = "no_guesses_left"
+	ErrCodeConflict           ErrorCode = "conflict"
+	ErrCodeInternal           ErrorCode = "internal_error"
+)
+
+// ErrorPayload represents the payload for an error message sent to a single client
+type ErrorPayload struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+}
+
 // GameLogic handles the core rules of the game
 type GameLogic struct{}
 
@@ -31,6 +103,36 @@ func NewGameLogic() *GameLogic {
 	return &GameLogic{}
 }
 
+// ValidateGuess checks that the guess is a pin of the configured length made up only of digits
+func (g *GameLogic) ValidateGuess(guess string, pinLength int) error {
+	if len(guess) != pinLength {
+		return fmt.Errorf("guess must be %d digits long", pinLength)
+	}
+	for _, char := range guess {
+		if char < '0' || char > '9' {
+			return fmt.Errorf("guess must contain only digits")
+		}
+	}
+	return nil
+}
+
+// GeneratePins returns count random pins of the given length, using crypto/rand
+func (g *GameLogic) GeneratePins(count, length int) ([]string, error) {
+	pins := make([]string, count)
+	for i := range pins {
+		digits := make([]byte, length)
+		for j := range digits {
+			n, err := rand.Int(rand.Reader, big.NewInt(10))
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate pin: %w", err)
+			}
+			digits[j] = byte('0' + n.Int64())
+		}
+		pins[i] = string(digits)
+	}
+	return pins, nil
+}
+
 // GenerateHints compares the guess against the correct pin and returns the feedback
 // 0 = Grey (Incorrect)
 // 1 = Orange (Correct digit, wrong position)
@@ -74,6 +176,146 @@ func (g *GameLogic) GenerateHints(guess, correctPin string) []int {
 	return hints
 }
 
+// GenerateHintsForConfig generates hints according to the room configuration.
+// With hints disabled, only Green and Grey are reported: misplaced digits show as Grey.
+func (g *GameLogic) GenerateHintsForConfig(guess, correctPin string, config *store.GameConfig) []int {
+	hints := g.GenerateHints(guess, correctPin)
+	if config != nil && config.HintsEnabled {
+		return hints
+	}
+
+	for i, hint := range hints {
+		if hint == 1 {
+			hints[i] = 0
+		}
+	}
+	return hints
+}
+
+// IsDecided reports whether the leading player has clinched the game,
+// i.e. their lead is larger than the number of rounds left to play
+func (g *GameLogic) IsDecided(scores map[string]int, remainingRounds int) bool {
+	first, second := 0, 0
+	for _, score := range scores {
+		if score > first {
+			first, second = score, first
+		} else if score > second {
+			second = score
+		}
+	}
+	return first-second > remainingRounds
+}
+
+// GuessesLeft returns how many guesses the player has left this round, or -1 if unlimited
+func (g *GameLogic) GuessesLeft(room *store.Room, playerID string) int {
+	if room.Config == nil || room.Config.MaxGuesses <= 0 {
+		return -1
+	}
+	used := 0
+	if stats, ok := room.RoundStats[playerID]; ok {
+		used = stats.Attempts
+	}
+	if used >= room.Config.MaxGuesses {
+		return 0
+	}
+	return room.Config.MaxGuesses - used
+}
+
+// RecordAttempt counts a guess towards the player's round stats
+func (g *GameLogic) RecordAttempt(room *store.Room, playerID string, at time.Time) {
+	if room.RoundStats == nil {
+		room.RoundStats = make(map[string]*store.PlayerStats)
+	}
+	stats, ok := room.RoundStats[playerID]
+	if !ok {
+		stats = &store.PlayerStats{}
+		room.RoundStats[playerID] = stats
+	}
+	stats.Attempts++
+	if !room.RoundStartedAt.IsZero() {
+		stats.TimeTakenMs = at.Sub(room.RoundStartedAt).Milliseconds()
+	}
+}
+
+// AddRoundStats adds the finished round's stats to the game totals and clears them.
+// The round winner's stats are also added to their totals across solved rounds, which break ties.
+func (g *GameLogic) AddRoundStats(room *store.Room, winnerID string) {
+	if len(room.RoundStats) == 0 {
+		return
+	}
+	if room.GameStats == nil {
+		room.GameStats = make(map[string]*store.PlayerStats)
+	}
+	for pid, stats := range room.RoundStats {
+		addStats(room.GameStats, pid, stats)
+	}
+	if stats, ok := room.RoundStats[winnerID]; ok && winnerID != "" {
+		if room.SolvedStats == nil {
+			room.SolvedStats = make(map[string]*store.PlayerStats)
+		}
+		addStats(room.SolvedStats, winnerID, stats)
+	}
+	room.RoundStats = nil
+}
+
+func addStats(totals map[string]*store.PlayerStats, playerID string, stats *store.PlayerStats) {
+	total, ok := totals[playerID]
+	if !ok {
+		total = &store.PlayerStats{}
+		totals[playerID] = total
+	}
+	total.Attempts += stats.Attempts
+	total.TimeTakenMs += stats.TimeTakenMs
+}
+
+// Tiebreak rules reported in game_end when round wins are level
+const (
+	TiebreakAttempts = "attempts" // Fewest guesses in the rounds each player won
+	TiebreakTime     = "time"     // Least time taken in the rounds each player won
+)
+
+// BreakTie picks a winner among players level on rounds won, from their stats across the rounds they won:
+// first by fewest attempts, then by least time taken. Returns an empty winner if they are still level,
+// or if a player has no stats to compare, so that nobody wins by not guessing.
+func (g *GameLogic) BreakTie(tied []string, stats map[string]*store.PlayerStats) (winnerID, rule string) {
+	if len(tied) < 2 {
+		return "", ""
+	}
+	for _, pid := range tied {
+		if s, ok := stats[pid]; !ok || s == nil {
+			return "", ""
+		}
+	}
+
+	total := func(pid string) store.PlayerStats {
+		return *stats[pid]
+	}
+
+	for _, r := range []struct {
+		rule  string
+		value func(store.PlayerStats) int64
+	}{
+		{TiebreakAttempts, func(s store.PlayerStats) int64 { return int64(s.Attempts) }},
+		{TiebreakTime, func(s store.PlayerStats) int64 { return s.TimeTakenMs }},
+	} {
+		best, bestValue, unique := "", int64(0), false
+		for _, pid := range tied {
+			v := r.value(total(pid))
+			switch {
+			case best == "" || v < bestValue:
+				best, bestValue, unique = pid, v, true
+			case v == bestValue:
+				unique = false
+			}
+		}
+		if unique {
+			return best, r.rule
+		}
+	}
+
+	return "", ""
+}
+
 // IsWin checks if the guess is exactly the correct pin
 func (g *GameLogic) IsWin(guess, correctPin string) bool {
 	return guess == correctPin
@@ -3,6 +3,8 @@ package socket
 import (
 	"reflect"
 	"testing"
+
+	"github.com/obasekietinosa/lockpick-api/internal/store"
 )
 
 func TestGenerateHints(t *testing.T) {
@@ -72,3 +74,217 @@ func TestGenerateHints(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateHintsForConfig_HintsDisabled(t *testing.T) {
+	logic := NewGameLogic()
+	config := &store.GameConfig{HintsEnabled: false}
+
+	tests := []struct {
+		name       string
+		guess      string
+		correctPin string
+		want       []int
+	}{
+		{
+			name:       "All Correct",
+			guess:      "123",
+			correctPin: "123",
+			want:       []int{2, 2, 2},
+		},
+		{
+			name:       "All Wrong",
+			guess:      "456",
+			correctPin: "123",
+			want:       []int{0, 0, 0},
+		},
+		{
+			name:       "One Green, One Misplaced",
+			guess:      "135",
+			correctPin: "123",
+			want:       []int{2, 0, 0},
+		},
+		{
+			name:       "All Misplaced",
+			guess:      "312",
+			correctPin: "123",
+			want:       []int{0, 0, 0},
+		},
+		{
+			name:       "Complex Case 1",
+			guess:      "1122",
+			correctPin: "1212",
+			want:       []int{2, 0, 0, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := logic.GenerateHintsForConfig(tt.guess, tt.correctPin, config); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GameLogic.GenerateHintsForConfig() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateHintsForConfig_HintsEnabled(t *testing.T) {
+	logic := NewGameLogic()
+	config := &store.GameConfig{HintsEnabled: true}
+
+	got := logic.GenerateHintsForConfig("1122", "1212", config)
+	want := logic.GenerateHints("1122", "1212")
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GameLogic.GenerateHintsForConfig() = %v, want %v", got, want)
+	}
+}
+
+func TestValidateGuess(t *testing.T) {
+	logic := NewGameLogic()
+
+	tests := []struct {
+		name      string
+		guess     string
+		pinLength int
+		wantErr   bool
+	}{
+		{name: "Valid", guess: "12345", pinLength: 5, wantErr: false},
+		{name: "Leading Zeros", guess: "00012", pinLength: 5, wantErr: false},
+		{name: "Too Short", guess: "1234", pinLength: 5, wantErr: true},
+		{name: "Too Long", guess: "123456", pinLength: 5, wantErr: true},
+		{name: "Empty", guess: "", pinLength: 5, wantErr: true},
+		{name: "Letters", guess: "12a45", pinLength: 5, wantErr: true},
+		{name: "Negative Sign", guess: "-1234", pinLength: 5, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := logic.ValidateGuess(tt.guess, tt.pinLength); (err != nil) != tt.wantErr {
+				t.Errorf("GameLogic.ValidateGuess() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIsDecided(t *testing.T) {
+	logic := NewGameLogic()
+
+	tests := []struct {
+		name      string
+		scores    map[string]int
+		remaining int
+		want      bool
+	}{
+		{name: "No Scores", scores: nil, remaining: 2, want: false},
+		{name: "Best Of 3 After One Win", scores: map[string]int{"p1": 1}, remaining: 2, want: false},
+		{name: "Best Of 3 Clinched", scores: map[string]int{"p1": 2}, remaining: 1, want: true},
+		{name: "Best Of 3 Tied Going Into Final", scores: map[string]int{"p1": 1, "p2": 1}, remaining: 1, want: false},
+		{name: "Best Of 5 Clinched Early", scores: map[string]int{"p1": 3, "p2": 0}, remaining: 2, want: true},
+		{name: "Best Of 5 Can Still Be Tied", scores: map[string]int{"p1": 2}, remaining: 2, want: false},
+		{name: "Final Round Played", scores: map[string]int{"p1": 1, "p2": 1}, remaining: 0, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := logic.IsDecided(tt.scores, tt.remaining); got != tt.want {
+				t.Errorf("GameLogic.IsDecided() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBreakTie(t *testing.T) {
+	logic := NewGameLogic()
+
+	tests := []struct {
+		name       string
+		tied       []string
+		stats      map[string]*store.PlayerStats
+		wantWinner string
+		wantRule   string
+	}{
+		{
+			name:       "Fewer Attempts",
+			tied:       []string{"p1", "p2"},
+			stats:      map[string]*store.PlayerStats{"p1": {Attempts: 7, TimeTakenMs: 1000}, "p2": {Attempts: 5, TimeTakenMs: 9000}},
+			wantWinner: "p2",
+			wantRule:   TiebreakAttempts,
+		},
+		{
+			name:       "Same Attempts, Faster",
+			tied:       []string{"p1", "p2"},
+			stats:      map[string]*store.PlayerStats{"p1": {Attempts: 5, TimeTakenMs: 4000}, "p2": {Attempts: 5, TimeTakenMs: 9000}},
+			wantWinner: "p1",
+			wantRule:   TiebreakTime,
+		},
+		{
+			name:       "Identical Stats",
+			tied:       []string{"p1", "p2"},
+			stats:      map[string]*store.PlayerStats{"p1": {Attempts: 5, TimeTakenMs: 4000}, "p2": {Attempts: 5, TimeTakenMs: 4000}},
+			wantWinner: "",
+			wantRule:   "",
+		},
+		{
+			name:       "Missing Stats Never Win",
+			tied:       []string{"p1", "p2"},
+			stats:      map[string]*store.PlayerStats{"p1": {Attempts: 2}},
+			wantWinner: "",
+			wantRule:   "",
+		},
+		{
+			name:       "Single Player",
+			tied:       []string{"p1"},
+			stats:      map[string]*store.PlayerStats{"p1": {Attempts: 2}},
+			wantWinner: "",
+			wantRule:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			winner, rule := logic.BreakTie(tt.tied, tt.stats)
+			if winner != tt.wantWinner || rule != tt.wantRule {
+				t.Errorf("GameLogic.BreakTie() = (%q, %q), want (%q, %q)", winner, rule, tt.wantWinner, tt.wantRule)
+			}
+		})
+	}
+}
+
+func TestAddRoundStats(t *testing.T) {
+	logic := NewGameLogic()
+	room := &store.Room{RoundStats: map[string]*store.PlayerStats{"p1": {Attempts: 3, TimeTakenMs: 2000}, "p2": {Attempts: 4, TimeTakenMs: 3000}}}
+
+	logic.AddRoundStats(room, "p1")
+
+	if room.GameStats["p1"].Attempts != 3 || room.GameStats["p2"].Attempts != 4 {
+		t.Errorf("Expected both players' stats in the game totals, got %+v", room.GameStats)
+	}
+	if len(room.SolvedStats) != 1 || room.SolvedStats["p1"].TimeTakenMs != 2000 {
+		t.Errorf("Expected only the winner's stats in the solved totals, got %+v", room.SolvedStats)
+	}
+	if room.RoundStats != nil {
+		t.Errorf("Expected round stats to be cleared, got %+v", room.RoundStats)
+	}
+}
+
+func TestRevealedPins(t *testing.T) {
+	pins := []string{"1111", "2222", "3333"}
+
+	tests := []struct {
+		name string
+		room *store.Room
+		want []string
+	}{
+		{name: "First Round", room: &store.Room{Status: "playing", CurrentRound: 1, RoundActive: true}, want: []string{}},
+		{name: "Between Rounds", room: &store.Room{Status: "playing", CurrentRound: 3}, want: []string{"1111", "2222"}},
+		{name: "Game Finished", room: &store.Room{Status: "finished", CurrentRound: 2}, want: []string{"1111", "2222"}},
+		{name: "Not Started", room: &store.Room{Status: "waiting", CurrentRound: 0}, want: []string{}},
+		{name: "Missing Pins", room: &store.Room{Status: "finished", CurrentRound: 5}, want: pins},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RevealedPins(tt.room, pins); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RevealedPins() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
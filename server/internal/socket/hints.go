@@ -0,0 +1,155 @@
+package socket
+
+import (
+	"github.com/obasekietinosa/lockpick-api/internal/store"
+)
+
+// Hint modes selectable through GameConfig.HintMode
+const (
+	HintModePositional  = "positional"
+	HintModeMastermind  = "mastermind"
+	HintModeHigherLower = "higher_lower"
+	HintModeDigitSum    = "digit_sum"
+)
+
+// HintStrategy produces the feedback sent to a player after a guess.
+// The shape of the hints depends on the mode and is sent as-is in guess_result.
+type HintStrategy interface {
+	Mode() string
+	Hints(guess, correctPin string) interface{}
+}
+
+// hintStrategies builds the strategy for each hint mode from the room config
+var hintStrategies = map[string]func(logic *GameLogic, config *store.GameConfig) HintStrategy{
+	HintModePositional: func(logic *GameLogic, config *store.GameConfig) HintStrategy {
+		return &PositionalHints{logic: logic, config: config}
+	},
+	HintModeMastermind: func(logic *GameLogic, config *store.GameConfig) HintStrategy {
+		return &MastermindHints{logic: logic, config: config}
+	},
+	HintModeHigherLower: func(logic *GameLogic, config *store.GameConfig) HintStrategy {
+		return &HigherLowerHints{config: config}
+	},
+	HintModeDigitSum: func(logic *GameLogic, config *store.GameConfig) HintStrategy {
+		return &DigitSumHints{}
+	},
+}
+
+// IsValidHintMode reports whether the mode is supported. An empty mode selects the default.
+func IsValidHintMode(mode string) bool {
+	if mode == "" {
+		return true
+	}
+	_, ok := hintStrategies[mode]
+	return ok
+}
+
+// HintStrategy returns the strategy for the room's hint mode, defaulting to positional hints
+func (g *GameLogic) HintStrategy(config *store.GameConfig) HintStrategy {
+	if config != nil {
+		if build, ok := hintStrategies[config.HintMode]; ok {
+			return build(g, config)
+		}
+	}
+	return hintStrategies[HintModePositional](g, config)
+}
+
+// PositionalHints reports Green/Orange/Grey for each position, see GenerateHints.
+// Orange is withheld unless hints are enabled.
+type PositionalHints struct {
+	logic  *GameLogic
+	config *store.GameConfig
+}
+
+func (s *PositionalHints) Mode() string { return HintModePositional }
+
+func (s *PositionalHints) Hints(guess, correctPin string) interface{} {
+	return s.logic.GenerateHintsForConfig(guess, correctPin, s.config)
+}
+
+// MastermindFeedback counts the exact and misplaced digits without revealing positions
+type MastermindFeedback struct {
+	Exact     int `json:"exact"`
+	Misplaced int `json:"misplaced"`
+}
+
+// MastermindHints reports aggregate counts of correct and misplaced digits.
+// Misplaced digits are only counted when hints are enabled.
+type MastermindHints struct {
+	logic  *GameLogic
+	config *store.GameConfig
+}
+
+func (s *MastermindHints) Mode() string { return HintModeMastermind }
+
+func (s *MastermindHints) Hints(guess, correctPin string) interface{} {
+	var feedback MastermindFeedback
+	for _, hint := range s.logic.GenerateHintsForConfig(guess, correctPin, s.config) {
+		switch hint {
+		case 2:
+			feedback.Exact++
+		case 1:
+			feedback.Misplaced++
+		}
+	}
+	return feedback
+}
+
+// Per-digit feedback values for HigherLowerHints
+const (
+	HintCorrect = "correct"
+	HintHigher  = "higher" // The correct digit is higher than the guessed one
+	HintLower   = "lower"  // The correct digit is lower than the guessed one
+	HintWrong   = "wrong"  // The digit is wrong, sent instead of higher or lower when hints are disabled
+)
+
+// HigherLowerHints reports, for each position, whether the correct digit is higher or lower.
+// The direction is only given when hints are enabled.
+type HigherLowerHints struct {
+	config *store.GameConfig
+}
+
+func (s *HigherLowerHints) Mode() string { return HintModeHigherLower }
+
+func (s *HigherLowerHints) Hints(guess, correctPin string) interface{} {
+	hints := make([]string, len(guess))
+	if len(guess) != len(correctPin) {
+		return hints
+	}
+	for i := range guess {
+		switch {
+		case guess[i] == correctPin[i]:
+			hints[i] = HintCorrect
+		case s.config == nil || !s.config.HintsEnabled:
+			hints[i] = HintWrong
+		case guess[i] < correctPin[i]:
+			hints[i] = HintHigher
+		default:
+			hints[i] = HintLower
+		}
+	}
+	return hints
+}
+
+// DigitSumFeedback holds the sum of the digits guessed in the correct position
+type DigitSumFeedback struct {
+	Sum int `json:"sum"`
+}
+
+// DigitSumHints reports only the sum of the correctly placed digits
+type DigitSumHints struct{}
+
+func (s *DigitSumHints) Mode() string { return HintModeDigitSum }
+
+func (s *DigitSumHints) Hints(guess, correctPin string) interface{} {
+	var feedback DigitSumFeedback
+	if len(guess) != len(correctPin) {
+		return feedback
+	}
+	for i := range guess {
+		if guess[i] == correctPin[i] {
+			feedback.Sum += int(guess[i] - '0')
+		}
+	}
+	return feedback
+}
@@ -0,0 +1,117 @@
+package socket
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/obasekietinosa/lockpick-api/internal/store"
+)
+
+func TestHintStrategy_Modes(t *testing.T) {
+	logic := NewGameLogic()
+
+	tests := []struct {
+		name       string
+		config     *store.GameConfig
+		guess      string
+		correctPin string
+		wantMode   string
+		want       interface{}
+	}{
+		{
+			name:       "Default Mode",
+			config:     &store.GameConfig{HintsEnabled: true},
+			guess:      "135",
+			correctPin: "123",
+			wantMode:   HintModePositional,
+			want:       []int{2, 1, 0},
+		},
+		{
+			name:       "Unknown Mode Falls Back To Positional",
+			config:     &store.GameConfig{HintMode: "psychic"},
+			guess:      "135",
+			correctPin: "123",
+			wantMode:   HintModePositional,
+			want:       []int{2, 0, 0},
+		},
+		{
+			name:       "Mastermind",
+			config:     &store.GameConfig{HintMode: HintModeMastermind, HintsEnabled: true},
+			guess:      "1122",
+			correctPin: "1212",
+			wantMode:   HintModeMastermind,
+			want:       MastermindFeedback{Exact: 2, Misplaced: 2},
+		},
+		{
+			name:       "Mastermind No Matches",
+			config:     &store.GameConfig{HintMode: HintModeMastermind, HintsEnabled: true},
+			guess:      "456",
+			correctPin: "123",
+			wantMode:   HintModeMastermind,
+			want:       MastermindFeedback{},
+		},
+		{
+			name:       "Higher Lower",
+			config:     &store.GameConfig{HintMode: HintModeHigherLower, HintsEnabled: true},
+			guess:      "195",
+			correctPin: "135",
+			wantMode:   HintModeHigherLower,
+			want:       []string{HintCorrect, HintLower, HintCorrect},
+		},
+		{
+			name:       "Higher Lower All Higher",
+			config:     &store.GameConfig{HintMode: HintModeHigherLower, HintsEnabled: true},
+			guess:      "000",
+			correctPin: "123",
+			wantMode:   HintModeHigherLower,
+			want:       []string{HintHigher, HintHigher, HintHigher},
+		},
+		{
+			name:       "Mastermind Without Hints",
+			config:     &store.GameConfig{HintMode: HintModeMastermind},
+			guess:      "1122",
+			correctPin: "1212",
+			wantMode:   HintModeMastermind,
+			want:       MastermindFeedback{Exact: 2},
+		},
+		{
+			name:       "Higher Lower Without Hints",
+			config:     &store.GameConfig{HintMode: HintModeHigherLower},
+			guess:      "195",
+			correctPin: "135",
+			wantMode:   HintModeHigherLower,
+			want:       []string{HintCorrect, HintWrong, HintCorrect},
+		},
+		{
+			name:       "Digit Sum",
+			config:     &store.GameConfig{HintMode: HintModeDigitSum},
+			guess:      "1947",
+			correctPin: "1347",
+			wantMode:   HintModeDigitSum,
+			want:       DigitSumFeedback{Sum: 12},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy := logic.HintStrategy(tt.config)
+			if strategy.Mode() != tt.wantMode {
+				t.Errorf("Expected mode %s, got %s", tt.wantMode, strategy.Mode())
+			}
+			if got := strategy.Hints(tt.guess, tt.correctPin); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Hints() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsValidHintMode(t *testing.T) {
+	for _, mode := range []string{"", HintModePositional, HintModeMastermind, HintModeHigherLower, HintModeDigitSum} {
+		if !IsValidHintMode(mode) {
+			t.Errorf("Expected %q to be valid", mode)
+		}
+	}
+	if IsValidHintMode("psychic") {
+		t.Error("Expected unknown mode to be invalid")
+	}
+}
@@ -0,0 +1,142 @@
+package socket
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/obasekietinosa/lockpick-api/internal/rating"
+	"github.com/obasekietinosa/lockpick-api/internal/store"
+)
+
+// RatingChange is how a finished game moved a player's rating, sent in game_end
+type RatingChange struct {
+	Rating float64 `json:"rating"`
+	Change float64 `json:"change"`
+}
+
+// NewProfile returns a profile with the initial rating. New players are handed one without it being stored,
+// so it is only saved once its first rated game ends.
+func NewProfile(profileID, name string) *store.Profile {
+	now := time.Now()
+	return &store.Profile{
+		ID:         profileID,
+		Name:       name,
+		Rating:     rating.Initial.Rating,
+		Deviation:  rating.Initial.Deviation,
+		Volatility: rating.Initial.Volatility,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+// LoadProfile returns the stored profile, or a new one if it has not finished a rated game yet
+func LoadProfile(ctx context.Context, s store.Store, profileID, name string) (*store.Profile, error) {
+	profile, err := s.GetProfile(ctx, profileID)
+	if errors.Is(err, store.ErrProfileNotFound) {
+		return NewProfile(profileID, name), nil
+	}
+	return profile, err
+}
+
+// updateRatings counts a finished game towards the profiles of both players, and returns the changes by player ID.
+// Each player is rated against the other's rating from before the game. Solo games, and games where a player
+// has no profile, are not rated.
+func (h *Hub) updateRatings(room *store.Room, winnerID string, isDraw bool) map[string]RatingChange {
+	if IsSolo(room.Config) {
+		return nil
+	}
+	ctx := context.Background()
+
+	playerIDs, err := h.store.GetRoomPlayers(ctx, room.ID)
+	if err != nil {
+		log.Printf("Error getting players to rate room %s: %v", room.ID, err)
+		return nil
+	}
+	if len(playerIDs) != 2 {
+		return nil
+	}
+
+	profiles := make([]*store.Profile, len(playerIDs))
+	for i, pid := range playerIDs {
+		player, err := h.store.GetPlayer(ctx, pid)
+		if err != nil {
+			log.Printf("Error getting player %s to rate room %s: %v", pid, room.ID, err)
+			return nil
+		}
+		if player.ProfileID == "" {
+			return nil
+		}
+		if profiles[i], err = LoadProfile(ctx, h.store, player.ProfileID, player.Name); err != nil {
+			log.Printf("Error getting profile %s to rate room %s: %v", player.ProfileID, room.ID, err)
+			return nil
+		}
+	}
+	// Playing against yourself is not rated
+	if profiles[0].ID == profiles[1].ID {
+		return nil
+	}
+
+	changes := make(map[string]RatingChange)
+	for i, pid := range playerIDs {
+		opponent := profiles[1-i]
+		change, err := h.rateProfile(ctx, profiles[i], ratingOf(opponent), gameScore(pid, winnerID, isDraw))
+		if err != nil {
+			log.Printf("Error rating profile %s after room %s: %v", profiles[i].ID, room.ID, err)
+			continue
+		}
+		changes[pid] = change
+	}
+	return changes
+}
+
+// rateProfile applies one game's result to the profile, which is read again if another game updated it first
+func (h *Hub) rateProfile(ctx context.Context, loaded *store.Profile, opponent rating.Rating, score float64) (RatingChange, error) {
+	var change RatingChange
+	err := store.RetryOnConflict(func() error {
+		profile, err := LoadProfile(ctx, h.store, loaded.ID, loaded.Name)
+		if err != nil {
+			return err
+		}
+
+		before := profile.Rating
+		updated := rating.Update(ratingOf(profile), []rating.Result{{Opponent: opponent, Score: score}})
+		profile.Rating = updated.Rating
+		profile.Deviation = updated.Deviation
+		profile.Volatility = updated.Volatility
+		profile.GamesPlayed++
+		switch score {
+		case rating.Win:
+			profile.Wins++
+		case rating.Loss:
+			profile.Losses++
+		default:
+			profile.Draws++
+		}
+		profile.UpdatedAt = time.Now()
+
+		if err := h.store.SaveProfile(ctx, profile); err != nil {
+			return err
+		}
+		change = RatingChange{Rating: profile.Rating, Change: profile.Rating - before}
+		return nil
+	})
+	return change, err
+}
+
+// gameScore is the player's result in the game as decided from the room's Scores, or by forfeit
+func gameScore(playerID, winnerID string, isDraw bool) float64 {
+	switch {
+	case isDraw:
+		return rating.Draw
+	case playerID == winnerID:
+		return rating.Win
+	default:
+		return rating.Loss
+	}
+}
+
+func ratingOf(profile *store.Profile) rating.Rating {
+	return rating.Rating{Rating: profile.Rating, Deviation: profile.Deviation, Volatility: profile.Volatility}
+}
@@ -0,0 +1,116 @@
+package socket
+
+import (
+	"testing"
+
+	"github.com/obasekietinosa/lockpick-api/internal/config"
+	"github.com/obasekietinosa/lockpick-api/internal/rating"
+	"github.com/obasekietinosa/lockpick-api/internal/store"
+)
+
+func newRatedProfile(id string) *store.Profile {
+	return &store.Profile{ID: id, Rating: rating.Initial.Rating, Deviation: rating.Initial.Deviation, Volatility: rating.Initial.Volatility}
+}
+
+func TestHub_GameEndUpdatesRatings(t *testing.T) {
+	memStore := store.NewMemoryStore()
+	hub := NewHub(&config.Config{}, memStore)
+	go hub.Run()
+
+	memStore.SaveRoom(nil, &store.Room{
+		ID:           "room1",
+		Status:       "playing",
+		Config:       &store.GameConfig{PinLength: 4, Rounds: 1},
+		CurrentRound: 1,
+		RoundActive:  true,
+	})
+	memStore.SaveProfile(nil, newRatedProfile("profile1"))
+	memStore.SaveProfile(nil, newRatedProfile("profile2"))
+	addPlayer(memStore, &store.Player{ID: "p1", RoomID: "room1", ProfileID: "profile1", Pins: []string{"4444"}})
+	addPlayer(memStore, &store.Player{ID: "p2", RoomID: "room1", ProfileID: "profile2", Pins: []string{"5555"}})
+
+	client := &Client{Hub: hub, Send: make(chan []byte, 10), RoomID: "room1", PlayerID: "p1"}
+	hub.Register <- client
+
+	hub.HandleMessage(client, GameMessage{Type: "guess", Payload: map[string]interface{}{"guess": "5555"}})
+
+	var msg GameMessage
+	for _, want := range []string{"guess_result", "round_end", "game_end"} {
+		if msg = readMessage(t, client); msg.Type != want {
+			t.Fatalf("Expected %s, got %s", want, msg.Type)
+		}
+	}
+
+	ratings, _ := msg.Payload.(map[string]interface{})["ratings"].(map[string]interface{})
+	winner, _ := ratings["p1"].(map[string]interface{})
+	loser, _ := ratings["p2"].(map[string]interface{})
+	if winner["change"].(float64) <= 0 || loser["change"].(float64) >= 0 {
+		t.Errorf("Expected the winner to gain and the loser to lose rating, got %v", ratings)
+	}
+
+	won, _ := memStore.GetProfile(nil, "profile1")
+	lost, _ := memStore.GetProfile(nil, "profile2")
+	if won.Rating <= rating.Initial.Rating || won.Wins != 1 || won.GamesPlayed != 1 {
+		t.Errorf("Expected the winner's profile to record a win, got %+v", won)
+	}
+	if lost.Rating >= rating.Initial.Rating || lost.Losses != 1 || lost.GamesPlayed != 1 {
+		t.Errorf("Expected the loser's profile to record a loss, got %+v", lost)
+	}
+}
+
+func TestHub_UpdateRatings_Unrated(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *store.GameConfig
+		profiles []string // Profile of p1 and p2
+	}{
+		{name: "Solo Game", config: &store.GameConfig{Mode: "solo"}, profiles: []string{"profile1", "profile2"}},
+		{name: "Player Without Profile", config: &store.GameConfig{}, profiles: []string{"profile1", ""}},
+		{name: "Same Profile", config: &store.GameConfig{}, profiles: []string{"profile1", "profile1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memStore := store.NewMemoryStore()
+			hub := NewHub(&config.Config{}, memStore)
+
+			room := &store.Room{ID: "room1", Status: "finished", Config: tt.config}
+			memStore.SaveRoom(nil, room)
+			memStore.SaveProfile(nil, newRatedProfile("profile1"))
+			memStore.SaveProfile(nil, newRatedProfile("profile2"))
+			addPlayer(memStore, &store.Player{ID: "p1", RoomID: "room1", ProfileID: tt.profiles[0]})
+			addPlayer(memStore, &store.Player{ID: "p2", RoomID: "room1", ProfileID: tt.profiles[1]})
+
+			if changes := hub.updateRatings(room, "p1", false); changes != nil {
+				t.Errorf("Expected no rating changes, got %v", changes)
+			}
+			if profile, _ := memStore.GetProfile(nil, "profile1"); profile.GamesPlayed != 0 {
+				t.Errorf("Expected the profile to be left alone, got %+v", profile)
+			}
+		})
+	}
+}
+
+func TestHub_UpdateRatings_StoresNewProfiles(t *testing.T) {
+	memStore := store.NewMemoryStore()
+	hub := NewHub(&config.Config{}, memStore)
+
+	// Neither profile has finished a rated game, so neither is stored yet
+	room := &store.Room{ID: "room1", Status: "finished", Config: &store.GameConfig{}}
+	memStore.SaveRoom(nil, room)
+	addPlayer(memStore, &store.Player{ID: "p1", Name: "Alice", RoomID: "room1", ProfileID: "profile1"})
+	addPlayer(memStore, &store.Player{ID: "p2", Name: "Bob", RoomID: "room1", ProfileID: "profile2"})
+
+	if changes := hub.updateRatings(room, "p1", false); len(changes) != 2 {
+		t.Fatalf("Expected both players to be rated, got %v", changes)
+	}
+
+	won, err := memStore.GetProfile(nil, "profile1")
+	if err != nil || won.Name != "Alice" || won.Wins != 1 || won.Rating <= rating.Initial.Rating {
+		t.Errorf("Expected the winner's profile to be stored with a win, got %+v, %v", won, err)
+	}
+	lost, err := memStore.GetProfile(nil, "profile2")
+	if err != nil || lost.Name != "Bob" || lost.Losses != 1 || lost.Rating >= rating.Initial.Rating {
+		t.Errorf("Expected the loser's profile to be stored with a loss, got %+v, %v", lost, err)
+	}
+}
@@ -0,0 +1,75 @@
+package socket
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/obasekietinosa/lockpick-api/internal/store"
+)
+
+// handleResume replies with a snapshot of the game so a reconnecting client can rebuild its state.
+func (h *Hub) handleResume(client *Client, payload ResumePayload) {
+	ctx := context.Background()
+
+	room, err := h.store.GetRoom(ctx, payload.RoomID)
+	if err != nil {
+		h.sendRoomError(client, err)
+		return
+	}
+
+	// Only the player's own guesses are replayed
+	guesses := []*store.GuessRecord{}
+	if room.CurrentRound > 0 {
+		all, err := h.store.ListGuesses(ctx, room.ID, room.CurrentRound)
+		if err != nil {
+			log.Printf("Error listing guesses: %v", err)
+			h.sendError(client, ErrCodeInternal, "Failed to get guesses")
+			return
+		}
+		for _, guess := range all {
+			if guess.PlayerID == payload.PlayerID {
+				guesses = append(guesses, guess)
+			}
+		}
+	}
+
+	ready := false
+	for _, pid := range room.ReadyPlayers {
+		if pid == payload.PlayerID {
+			ready = true
+			break
+		}
+	}
+
+	h.sendToClient(client, GameMessage{
+		Type: "state_snapshot",
+		Payload: map[string]interface{}{
+			"room_id":        room.ID,
+			"player_id":      payload.PlayerID,
+			"status":         room.Status,
+			"round":          room.CurrentRound,
+			"round_active":   room.RoundActive,
+			"scores":         room.Scores,
+			"guesses":        guesses,
+			"guesses_left":   h.gameLogic.GuessesLeft(room, payload.PlayerID),
+			"time_remaining": timeRemaining(room, time.Now()),
+			"current_turn":   room.CurrentTurn,
+			"ready":          ready,
+			"ready_players":  room.ReadyPlayers,
+		},
+	})
+}
+
+// timeRemaining returns the whole seconds left on the round timer, or -1 if no timer is running
+func timeRemaining(room *store.Room, now time.Time) int {
+	if !room.RoundActive || room.Config == nil || room.Config.TimerDuration <= 0 || room.RoundStartedAt.IsZero() {
+		return -1
+	}
+	deadline := room.RoundStartedAt.Add(time.Duration(room.Config.TimerDuration) * time.Second)
+	remaining := int(deadline.Sub(now).Seconds())
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
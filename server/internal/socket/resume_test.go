@@ -0,0 +1,102 @@
+package socket
+
+import (
+	"testing"
+	"time"
+
+	"github.com/obasekietinosa/lockpick-api/internal/config"
+	"github.com/obasekietinosa/lockpick-api/internal/store"
+)
+
+func TestHub_HandleResume(t *testing.T) {
+	memStore := store.NewMemoryStore()
+	hub := NewHub(&config.Config{}, memStore)
+	go hub.Run()
+
+	roomID := "room1"
+	memStore.SaveRoom(nil, &store.Room{
+		ID:             roomID,
+		Status:         "playing",
+		Config:         &store.GameConfig{PinLength: 5, TimerDuration: 60},
+		CurrentRound:   1,
+		RoundActive:    true,
+		RoundStartedAt: time.Now().Add(-20 * time.Second),
+		Scores:         map[string]int{"p1": 0, "p2": 1},
+		ReadyPlayers:   []string{},
+	})
+	memStore.AppendGuess(nil, roomID, 1, &store.GuessRecord{PlayerID: "p1", Guess: "11111"})
+	memStore.AppendGuess(nil, roomID, 1, &store.GuessRecord{PlayerID: "p2", Guess: "22222"})
+	memStore.AppendGuess(nil, roomID, 1, &store.GuessRecord{PlayerID: "p1", Guess: "33333"})
+
+	client := &Client{Hub: hub, Send: make(chan []byte, 10), RoomID: roomID, PlayerID: "p1"}
+	hub.Register <- client
+
+	hub.HandleMessage(client, GameMessage{Type: "resume", Payload: map[string]interface{}{}})
+
+	msg := readMessage(t, client)
+	if msg.Type != "state_snapshot" {
+		t.Fatalf("Expected state_snapshot, got %s", msg.Type)
+	}
+	payload := msg.Payload.(map[string]interface{})
+	if payload["round"] != float64(1) {
+		t.Errorf("Expected round 1, got %v", payload["round"])
+	}
+	if payload["round_active"] != true {
+		t.Errorf("Expected round to be active")
+	}
+
+	guesses := payload["guesses"].([]interface{})
+	if len(guesses) != 2 {
+		t.Fatalf("Expected only the player's 2 guesses, got %d", len(guesses))
+	}
+	for _, g := range guesses {
+		if g.(map[string]interface{})["player_id"] != "p1" {
+			t.Errorf("Snapshot leaked an opponent guess: %v", g)
+		}
+	}
+
+	remaining := payload["time_remaining"].(float64)
+	if remaining <= 30 || remaining > 40 {
+		t.Errorf("Expected about 40 seconds remaining, got %v", remaining)
+	}
+}
+
+func TestHub_HandleResume_RoomNotFound(t *testing.T) {
+	hub := NewHub(&config.Config{}, store.NewMemoryStore())
+	go hub.Run()
+
+	client := &Client{Hub: hub, Send: make(chan []byte, 10), RoomID: "missing", PlayerID: "p1"}
+	hub.Register <- client
+
+	hub.HandleMessage(client, GameMessage{Type: "resume", Payload: map[string]interface{}{}})
+
+	msg := readMessage(t, client)
+	if msg.Type != "error" {
+		t.Fatalf("Expected error, got %s", msg.Type)
+	}
+	if code := msg.Payload.(map[string]interface{})["code"]; code != string(ErrCodeRoomNotFound) {
+		t.Errorf("Expected code %s, got %v", ErrCodeRoomNotFound, code)
+	}
+}
+
+func TestTimeRemaining(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		room     *store.Room
+		expected int
+	}{
+		{"No Timer", &store.Room{RoundActive: true, Config: &store.GameConfig{}, RoundStartedAt: now}, -1},
+		{"Round Not Active", &store.Room{Config: &store.GameConfig{TimerDuration: 30}, RoundStartedAt: now}, -1},
+		{"Running", &store.Room{RoundActive: true, Config: &store.GameConfig{TimerDuration: 30}, RoundStartedAt: now.Add(-10 * time.Second)}, 20},
+		{"Expired", &store.Room{RoundActive: true, Config: &store.GameConfig{TimerDuration: 30}, RoundStartedAt: now.Add(-time.Minute)}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := timeRemaining(tt.room, now); got != tt.expected {
+				t.Errorf("Expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
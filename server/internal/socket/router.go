@@ -0,0 +1,71 @@
+package socket
+
+import (
+	"encoding/json"
+	"log"
+)
+
+// messageHandler processes a single client message.
+type messageHandler func(h *Hub, client *Client, payload interface{})
+
+// sessionPayload is implemented by payloads that name the room and player they act for.
+type sessionPayload interface {
+	bindSession(client *Client) bool
+}
+
+// messageHandlers routes client messages to their handler, keyed by message type.
+var messageHandlers = map[string]messageHandler{
+	"guess":        typedHandler((*Hub).handleGuess),
+	"player_ready": typedHandler((*Hub).handlePlayerReady),
+	"resume":       typedHandler((*Hub).handleResume),
+}
+
+// typedHandler wraps a handler so that it receives its payload decoded into T.
+// Payloads implementing sessionPayload are checked against the client's session first.
+func typedHandler[T any](fn func(h *Hub, client *Client, payload T)) messageHandler {
+	return func(h *Hub, client *Client, raw interface{}) {
+		// Payload is map[string]interface{}
+		// Roundtrip via JSON to decode into struct safely
+		payloadBytes, err := json.Marshal(raw)
+		if err != nil {
+			log.Printf("Error marshaling payload: %v", err)
+			h.sendError(client, ErrCodeInvalidPayload, "Payload could not be read")
+			return
+		}
+		var payload T
+		if err := json.Unmarshal(payloadBytes, &payload); err != nil {
+			log.Printf("Error unmarshaling payload: %v", err)
+			h.sendError(client, ErrCodeInvalidPayload, "Payload could not be read")
+			return
+		}
+		if p, ok := any(&payload).(sessionPayload); ok && !p.bindSession(client) {
+			h.sendError(client, ErrCodeUnauthorized, "Payload does not match the session identity")
+			return
+		}
+		fn(h, client, payload)
+	}
+}
+
+// bindSession checks that the identity named in the payload matches the client's session.
+// Empty fields are filled in from the session.
+func bindSession(client *Client, roomID, playerID *string) bool {
+	if *roomID == "" {
+		*roomID = client.RoomID
+	}
+	if *playerID == "" {
+		*playerID = client.PlayerID
+	}
+	return *roomID == client.RoomID && *playerID == client.PlayerID
+}
+
+func (p *GuessPayload) bindSession(client *Client) bool {
+	return bindSession(client, &p.RoomID, &p.PlayerID)
+}
+
+func (p *PlayerReadyPayload) bindSession(client *Client) bool {
+	return bindSession(client, &p.RoomID, &p.PlayerID)
+}
+
+func (p *ResumePayload) bindSession(client *Client) bool {
+	return bindSession(client, &p.RoomID, &p.PlayerID)
+}
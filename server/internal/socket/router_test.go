@@ -0,0 +1,91 @@
+package socket
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/obasekietinosa/lockpick-api/internal/config"
+	"github.com/obasekietinosa/lockpick-api/internal/store"
+)
+
+// readMessage waits for the next message delivered to the client
+func readMessage(t *testing.T, client *Client) GameMessage {
+	t.Helper()
+	return readMessageWithin(t, client, 1)
+}
+
+// readMessageWithin waits up to the given number of seconds for the next message delivered to the client
+func readMessageWithin(t *testing.T, client *Client, seconds int) GameMessage {
+	t.Helper()
+	select {
+	case msgBytes := <-client.Send:
+		var msg GameMessage
+		if err := json.Unmarshal(msgBytes, &msg); err != nil {
+			t.Fatalf("Failed to unmarshal message: %v", err)
+		}
+		return msg
+	case <-time.After(time.Duration(seconds) * time.Second):
+		t.Fatal("Timeout waiting for message")
+	}
+	return GameMessage{}
+}
+
+// addPlayer saves the player and adds them to their room
+func addPlayer(s store.Store, player *store.Player) {
+	s.SavePlayer(context.Background(), player)
+	s.AddPlayerToRoom(context.Background(), player.RoomID, player.ID)
+}
+
+func TestHub_HandleMessage_UnknownType(t *testing.T) {
+	hub := NewHub(&config.Config{}, store.NewMemoryStore())
+	go hub.Run()
+
+	client := &Client{Hub: hub, Send: make(chan []byte, 10), RoomID: "room1", PlayerID: "p1"}
+	hub.Register <- client
+
+	hub.HandleMessage(client, GameMessage{Type: "dance"})
+
+	msg := readMessage(t, client)
+	if msg.Type != "error" {
+		t.Fatalf("Expected error, got %s", msg.Type)
+	}
+	payload := msg.Payload.(map[string]interface{})
+	if payload["code"] != string(ErrCodeUnknownMessageType) {
+		t.Errorf("Expected code %s, got %v", ErrCodeUnknownMessageType, payload["code"])
+	}
+}
+
+func TestHub_HandleMessage_PlayerReady(t *testing.T) {
+	memStore := store.NewMemoryStore()
+	hub := NewHub(&config.Config{}, memStore)
+	go hub.Run()
+
+	roomID := "room1"
+	memStore.SaveRoom(nil, &store.Room{
+		ID:           roomID,
+		Config:       &store.GameConfig{},
+		CurrentRound: 2,
+	})
+	addPlayer(memStore, &store.Player{ID: "p1", RoomID: roomID})
+	addPlayer(memStore, &store.Player{ID: "p2", RoomID: roomID})
+
+	client1 := &Client{Hub: hub, Send: make(chan []byte, 10), RoomID: roomID, PlayerID: "p1"}
+	client2 := &Client{Hub: hub, Send: make(chan []byte, 10), RoomID: roomID, PlayerID: "p2"}
+	hub.Register <- client1
+	hub.Register <- client2
+
+	// Payloads decoded from the socket are generic maps
+	hub.HandleMessage(client1, GameMessage{Type: "player_ready", Payload: map[string]interface{}{}})
+	hub.HandleMessage(client2, GameMessage{Type: "player_ready", Payload: map[string]interface{}{"room_id": roomID, "player_id": "p2"}})
+
+	msg := readMessage(t, client1)
+	if msg.Type != "round_start" {
+		t.Fatalf("Expected round_start, got %s", msg.Type)
+	}
+	payload := msg.Payload.(map[string]interface{})
+	if int(payload["round"].(float64)) != 2 {
+		t.Errorf("Expected round 2, got %v", payload["round"])
+	}
+}
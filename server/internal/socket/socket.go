@@ -3,10 +3,13 @@ package socket
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"sync"
 	"time"
 
+	"github.com/obasekietinosa/lockpick-api/internal/auth"
 	"github.com/obasekietinosa/lockpick-api/internal/config"
 	"github.com/obasekietinosa/lockpick-api/internal/store"
 )
@@ -18,15 +21,42 @@ type Hub struct {
 	// Game logic helper
 	gameLogic *GameLogic
 
+	// Verifies the session tokens presented when connecting
+	sessions *auth.Signer
+
 	// Registered clients.
 	Clients map[*Client]bool
 
+	// Registered clients grouped by the room they are subscribed to.
+	rooms map[string]map[*Client]bool
+
 	// Room timers
-	timers map[string]context.CancelFunc
-	mu     sync.Mutex
+	timers map[string]roundTimer
+
+	// Per-turn timers for turn-based rooms
+	turnTimers map[string]roundTimer
+	mu         sync.Mutex
+
+	// Grace timers for disconnected players, keyed by room and player.
+	// Guarded by their own mutex because Run takes it, and Run must never wait on mu.
+	graceTimers map[string]context.CancelFunc
+	graceMu     sync.Mutex
+
+	// How long a disconnected player has to reconnect before forfeiting
+	disconnectGrace time.Duration
+
+	// Called when a player loses their last connection to a room. Set before Run starts.
+	disconnectHandlers []func(roomID, playerID string)
+
+	// Totals reclaimed by the sweeper
+	sweepStats SweepStats
+	statsMu    sync.Mutex
 
-	// Inbound messages from the clients.
-	Broadcast chan []byte
+	// Outbound messages addressed to a single room.
+	broadcast chan roomMessage
+
+	// Outbound messages addressed to a single client.
+	direct chan clientMessage
 
 	// Register requests from the clients.
 	Register chan *Client
@@ -35,15 +65,41 @@ type Hub struct {
 	Unregister chan *Client
 }
 
+// roomMessage is an encoded message together with the room it is addressed to.
+type roomMessage struct {
+	roomID string
+	data   []byte
+}
+
+// clientMessage is an encoded message together with the client it is addressed to.
+type clientMessage struct {
+	client *Client
+	data   []byte
+}
+
+// DefaultDisconnectGrace is used when the config does not set a positive grace period
+const DefaultDisconnectGrace = 30 * time.Second
+
 func NewHub(cfg *config.Config, store store.Store) *Hub {
+	grace := time.Duration(cfg.DisconnectGracePeriod) * time.Second
+	if grace <= 0 {
+		grace = DefaultDisconnectGrace
+	}
+
 	return &Hub{
-		Broadcast:  make(chan []byte),
-		Register:   make(chan *Client),
-		Unregister: make(chan *Client),
-		Clients:    make(map[*Client]bool),
-		timers:     make(map[string]context.CancelFunc),
-		store:      store,
-		gameLogic:  NewGameLogic(),
+		broadcast:       make(chan roomMessage),
+		direct:          make(chan clientMessage),
+		Register:        make(chan *Client),
+		Unregister:      make(chan *Client),
+		Clients:         make(map[*Client]bool),
+		rooms:           make(map[string]map[*Client]bool),
+		timers:          make(map[string]roundTimer),
+		turnTimers:      make(map[string]roundTimer),
+		graceTimers:     make(map[string]context.CancelFunc),
+		disconnectGrace: grace,
+		store:           store,
+		gameLogic:       NewGameLogic(),
+		sessions:        auth.NewSigner([]byte(cfg.SessionSecret)),
 	}
 }
 
@@ -52,54 +108,128 @@ func (h *Hub) Run() {
 		select {
 		case client := <-h.Register:
 			h.Clients[client] = true
+			if client.RoomID != "" {
+				if h.rooms[client.RoomID] == nil {
+					h.rooms[client.RoomID] = make(map[*Client]bool)
+				}
+				h.rooms[client.RoomID][client] = true
+				h.cancelGraceTimer(client.RoomID, client.PlayerID)
+			}
 		case client := <-h.Unregister:
 			if _, ok := h.Clients[client]; ok {
-				delete(h.Clients, client)
-				close(client.Send)
+				h.removeClient(client)
 			}
-		case message := <-h.Broadcast:
-			log.Printf("Hub received broadcast message. Active clients: %d", len(h.Clients))
-			for client := range h.Clients {
+		case message := <-h.broadcast:
+			subscribers := h.rooms[message.roomID]
+			log.Printf("Hub routing message to room %s. Subscribed clients: %d", message.roomID, len(subscribers))
+			for client := range subscribers {
 				select {
-				case client.Send <- message:
+				case client.Send <- message.data:
 				default:
-					close(client.Send)
-					delete(h.Clients, client)
+					h.removeClient(client)
 				}
 			}
+		case message := <-h.direct:
+			if _, ok := h.Clients[message.client]; !ok {
+				continue
+			}
+			select {
+			case message.client.Send <- message.data:
+			default:
+				h.removeClient(message.client)
+			}
 		}
 	}
 }
 
-func (h *Hub) HandleMessage(client *Client, msg GameMessage) {
-	switch msg.Type {
-	case "guess":
-		// Payload is map[string]interface{}
-		// Roundtrip via JSON to decode into struct safely
-		payloadBytes, err := json.Marshal(msg.Payload)
-		if err != nil {
-			log.Printf("Error marshaling payload: %v", err)
-			return
+// removeClient drops a client from the hub and its room, and closes its send channel.
+// It must only be called from the Run goroutine.
+func (h *Hub) removeClient(client *Client) {
+	delete(h.Clients, client)
+	if subscribers, ok := h.rooms[client.RoomID]; ok {
+		delete(subscribers, client)
+		if len(subscribers) == 0 {
+			delete(h.rooms, client.RoomID)
 		}
-		var payload GuessPayload
-		if err := json.Unmarshal(payloadBytes, &payload); err != nil {
-			log.Printf("Error unmarshaling payload: %v", err)
-			return
+		if !h.isConnected(client.RoomID, client.PlayerID) {
+			h.startGraceTimer(client.RoomID, client.PlayerID)
+			h.notifyDisconnect(client.RoomID, client.PlayerID)
 		}
-		h.handleGuess(client, payload)
-	case "player_ready":
-		payloadBytes, err := json.Marshal(msg.Payload)
-		if err != nil {
-			log.Printf("Error marshaling payload: %v", err)
-			return
-		}
-		var payload PlayerReadyPayload
-		if err := json.Unmarshal(payloadBytes, &payload); err != nil {
-			log.Printf("Error unmarshaling payload: %v", err)
-			return
+	}
+	close(client.Send)
+}
+
+// isConnected reports whether the player still has a connection subscribed to the room.
+// It must only be called from the Run goroutine.
+func (h *Hub) isConnected(roomID, playerID string) bool {
+	for client := range h.rooms[roomID] {
+		if client.PlayerID == playerID {
+			return true
 		}
-		h.handlePlayerReady(client, payload)
 	}
+	return false
+}
+
+// BroadcastToRoom sends a message to every client subscribed to the given room.
+func (h *Hub) BroadcastToRoom(roomID string, msg GameMessage) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("Error marshaling %s message: %v", msg.Type, err)
+		return
+	}
+	h.broadcast <- roomMessage{roomID: roomID, data: data}
+}
+
+// sendToClient sends a message to a single client.
+func (h *Hub) sendToClient(client *Client, msg GameMessage) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("Error marshaling %s message: %v", msg.Type, err)
+		return
+	}
+	h.direct <- clientMessage{client: client, data: data}
+}
+
+// sendError replies to the originating client with an error message.
+func (h *Hub) sendError(client *Client, code ErrorCode, message string) {
+	h.sendToClient(client, GameMessage{
+		Type:    "error",
+		Payload: ErrorPayload{Code: code, Message: message},
+	})
+}
+
+// sendRoomError reports a failed room lookup to a client
+func (h *Hub) sendRoomError(client *Client, err error) {
+	if errors.Is(err, store.ErrRoomNotFound) {
+		h.sendError(client, ErrCodeRoomNotFound, "Room not found")
+		return
+	}
+	log.Printf("Error getting room: %v", err)
+	h.sendError(client, ErrCodeInternal, "Failed to get room")
+}
+
+// sendSaveError reports a room update that could not be saved to a client
+func (h *Hub) sendSaveError(client *Client, err error, message string) {
+	if err == nil {
+		return
+	}
+	if errors.Is(err, store.ErrConflict) {
+		h.sendError(client, ErrCodeConflict, "The game changed at the same time, please try again")
+		return
+	}
+	log.Printf("%s: %v", message, err)
+	h.sendError(client, ErrCodeInternal, message)
+}
+
+// HandleMessage dispatches a client message to the handler registered for its type.
+func (h *Hub) HandleMessage(client *Client, msg GameMessage) {
+	handler, ok := messageHandlers[msg.Type]
+	if !ok {
+		log.Printf("Unknown message type: %s", msg.Type)
+		h.sendError(client, ErrCodeUnknownMessageType, fmt.Sprintf("Unknown message type: %s", msg.Type))
+		return
+	}
+	handler(h, client, msg.Payload)
 }
 
 func (h *Hub) handlePlayerReady(client *Client, payload PlayerReadyPayload) {
@@ -107,18 +237,33 @@ func (h *Hub) handlePlayerReady(client *Client, payload PlayerReadyPayload) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	// Check if round is already active (timer running)
-	if _, active := h.timers[payload.RoomID]; active {
-		log.Printf("Player %s tried to ready up, but round is already active", payload.PlayerID)
-		return
-	}
+	err := store.RetryOnConflict(func() error {
+		return h.readyLocked(client, payload)
+	})
+	h.sendSaveError(client, err, "Failed to save ready status")
+}
 
+// readyLocked marks the player as ready and starts the round once everyone is.
+// It reports validation errors to the client itself and only returns errors from saving the room.
+func (h *Hub) readyLocked(client *Client, payload PlayerReadyPayload) error {
 	ctx := context.Background()
 
 	room, err := h.store.GetRoom(ctx, payload.RoomID)
 	if err != nil {
-		log.Printf("Error getting room: %v", err)
-		return
+		h.sendRoomError(client, err)
+		return nil
+	}
+
+	if room.Status == "finished" {
+		h.sendError(client, ErrCodeGameFinished, "The game has already finished")
+		return nil
+	}
+
+	// Check if round is already active
+	if room.RoundActive {
+		log.Printf("Player %s tried to ready up, but round is already active", payload.PlayerID)
+		h.sendError(client, ErrCodeRoundInProgress, "The round has already started")
+		return nil
 	}
 
 	// Add player to ReadyPlayers if not already present
@@ -129,26 +274,40 @@ func (h *Hub) handlePlayerReady(client *Client, payload PlayerReadyPayload) {
 			break
 		}
 	}
-
 	if !alreadyReady {
 		room.ReadyPlayers = append(room.ReadyPlayers, payload.PlayerID)
-		if err := h.store.SaveRoom(ctx, room); err != nil {
-			log.Printf("Error saving room: %v", err)
-			return
-		}
 	}
 
 	// Check if both players are ready
 	players, err := h.store.GetRoomPlayers(ctx, room.ID)
 	if err != nil {
 		log.Printf("Error getting players: %v", err)
-		return
+		h.sendError(client, ErrCodeInternal, "Failed to get room players")
+		return nil
 	}
 
-	if len(room.ReadyPlayers) >= len(players) {
-		// All players ready, start the round
+	// In solo games the server's player is always ready
+	required := len(players)
+	if IsSolo(room.Config) {
+		required = 1
+	}
 
-		// Start Round
+	allReady := len(room.ReadyPlayers) >= required
+	if allReady {
+		// Reset ReadyPlayers and open the round for guesses
+		room.ReadyPlayers = []string{}
+		OpenRound(room, time.Now())
+	}
+
+	if alreadyReady && !allReady {
+		return nil
+	}
+	if err := h.store.SaveRoom(ctx, room); err != nil {
+		return err
+	}
+
+	if allReady {
+		// All players ready, start the round
 		startMsg := GameMessage{
 			Type: "round_start",
 			Payload: map[string]interface{}{
@@ -156,17 +315,11 @@ func (h *Hub) handlePlayerReady(client *Client, payload PlayerReadyPayload) {
 				"round":   room.CurrentRound,
 			},
 		}
-		startBytes, _ := json.Marshal(startMsg)
-		h.Broadcast <- startBytes
-
-		// Reset ReadyPlayers
-		room.ReadyPlayers = []string{}
-		if err := h.store.SaveRoom(ctx, room); err != nil {
-			log.Printf("Error saving room: %v", err)
-		}
+		h.BroadcastToRoom(room.ID, startMsg)
 
-		h.startRoundTimerLocked(room.ID)
+		h.startRoundLocked(room.ID)
 	}
+	return nil
 }
 
 func (h *Hub) StartRoundTimer(roomID string) {
@@ -188,13 +341,13 @@ func (h *Hub) startRoundTimerLocked(roomID string) {
 	}
 
 	// Cancel existing timer if any
-	if cancel, ok := h.timers[roomID]; ok {
-		cancel()
+	if timer, ok := h.timers[roomID]; ok {
+		timer.cancel()
 	}
 
 	// Create new timer context
 	timerCtx, cancel := context.WithCancel(context.Background())
-	h.timers[roomID] = cancel
+	h.timers[roomID] = roundTimer{round: room.CurrentRound, cancel: cancel}
 
 	go func() {
 		select {
@@ -209,41 +362,111 @@ func (h *Hub) startRoundTimerLocked(roomID string) {
 }
 
 func (h *Hub) handleRoundTimeout(roomID string, roundNumber int) {
-	ctx := context.Background()
-	room, err := h.store.GetRoom(ctx, roomID)
+	err := store.RetryOnConflict(func() error {
+		ctx := context.Background()
+		room, err := h.store.GetRoom(ctx, roomID)
+		if err != nil {
+			log.Printf("Error getting room for timeout: %v", err)
+			return nil
+		}
+
+		// Check if round is still the same and the game has not ended some other way (race condition check)
+		if room.CurrentRound != roundNumber || room.Status == "finished" {
+			return nil
+		}
+
+		log.Printf("Round %d timed out for room %s", roundNumber, roomID)
+
+		// In solo games running out of time loses the round
+		if IsSolo(room.Config) {
+			houseID, err := h.houseID(ctx, room)
+			if err != nil {
+				log.Printf("Error getting house player for timeout: %v", err)
+				return nil
+			}
+			if room.Scores == nil {
+				room.Scores = make(map[string]int)
+			}
+			room.Scores[houseID]++
+			return h.handleRoundEnd(room, houseID, RoundEndTimeout)
+		}
+
+		// Trigger Draw
+		return h.handleRoundEnd(room, "", RoundEndTimeout)
+	})
 	if err != nil {
-		log.Printf("Error getting room for timeout: %v", err)
-		return
+		log.Printf("Error ending round %d of room %s on timeout: %v", roundNumber, roomID, err)
 	}
+}
 
-	// Check if round is still the same (race condition check)
-	if room.CurrentRound != roundNumber {
-		return
+// houseID returns the server's player in a solo game, which is the player that is not the host
+func (h *Hub) houseID(ctx context.Context, room *store.Room) (string, error) {
+	players, err := h.store.GetRoomPlayers(ctx, room.ID)
+	if err != nil {
+		return "", err
 	}
-
-	log.Printf("Round %d timed out for room %s", roundNumber, roomID)
-
-	// Trigger Draw
-	h.handleRoundEnd(room, "")
+	for _, pid := range players {
+		if pid != room.HostID {
+			return pid, nil
+		}
+	}
+	return "", fmt.Errorf("room %s has no house player", room.ID)
 }
 
 func (h *Hub) handleGuess(client *Client, payload GuessPayload) {
 	log.Printf("Handling guess from room %s: %s", payload.RoomID, payload.Guess)
 
+	err := store.RetryOnConflict(func() error {
+		return h.tryGuess(client, payload)
+	})
+	h.sendSaveError(client, err, "Failed to save guess")
+}
+
+// tryGuess scores a guess against the room as it is now.
+// It reports validation errors to the client itself and only returns errors from saving the room,
+// so that a guess racing another update of the room can be tried again.
+func (h *Hub) tryGuess(client *Client, payload GuessPayload) error {
 	ctx := context.Background()
 
 	// 1. Fetch Room
 	room, err := h.store.GetRoom(ctx, payload.RoomID)
 	if err != nil {
-		log.Printf("Error getting room: %v", err)
-		return
+		h.sendRoomError(client, err)
+		return nil
+	}
+
+	if room.Status == "finished" {
+		h.sendError(client, ErrCodeGameFinished, "The game has already finished")
+		return nil
+	}
+
+	// Guesses are only accepted between round_start and round_end
+	if room.Status != "playing" || !room.RoundActive {
+		h.sendError(client, ErrCodeRoundNotActive, "No round is in progress")
+		return nil
+	}
+
+	if err := h.gameLogic.ValidateGuess(payload.Guess, room.Config.PinLength); err != nil {
+		h.sendError(client, ErrCodeInvalidGuess, err.Error())
+		return nil
+	}
+
+	if room.Config.TurnBased && room.CurrentTurn != payload.PlayerID {
+		h.sendError(client, ErrCodeNotYourTurn, "It is not your turn")
+		return nil
+	}
+
+	if h.gameLogic.GuessesLeft(room, payload.PlayerID) == 0 {
+		h.sendError(client, ErrCodeNoGuessesLeft, "You have no guesses left this round")
+		return nil
 	}
 
 	// 2. Identify Current Player and Opponent
 	players, err := h.store.GetRoomPlayers(ctx, payload.RoomID)
 	if err != nil {
 		log.Printf("Error getting players: %v", err)
-		return
+		h.sendError(client, ErrCodeInternal, "Failed to get room players")
+		return nil
 	}
 
 	var opponentID string
@@ -253,7 +476,8 @@ func (h *Hub) handleGuess(client *Client, payload GuessPayload) {
 
 	if len(players) != 2 {
 		log.Printf("Room %s does not have 2 players", payload.RoomID)
-		return
+		h.sendError(client, ErrCodeRoundNotActive, "Waiting for an opponent to join")
+		return nil
 	}
 
 	for _, pid := range players {
@@ -266,70 +490,173 @@ func (h *Hub) handleGuess(client *Client, payload GuessPayload) {
 	opponent, err := h.store.GetPlayer(ctx, opponentID)
 	if err != nil {
 		log.Printf("Error getting opponent: %v", err)
-		return
+		h.sendError(client, ErrCodeInternal, "Failed to get opponent")
+		return nil
 	}
 
-	if room.CurrentRound < 1 || room.CurrentRound > 3 {
+	if room.CurrentRound < 1 || room.CurrentRound > room.Config.TotalRounds() {
 		// Auto-correct if 0
 		if room.CurrentRound == 0 {
+			// Saved along with the attempt to prevent future issues
 			room.CurrentRound = 1
-			// Save the correction to prevent future issues
-			if err := h.store.SaveRoom(ctx, room); err != nil {
-				log.Printf("Error saving room correction: %v", err)
-			}
 		} else {
 			log.Printf("Invalid round number: %d", room.CurrentRound)
-			return
+			h.sendError(client, ErrCodeRoundNotActive, fmt.Sprintf("Round %d is not active", room.CurrentRound))
+			return nil
 		}
 	}
 
 	// Pins are 0-indexed, so round 1 is index 0
 	if len(opponent.Pins) < room.CurrentRound {
 		log.Printf("Opponent does not have enough pins for round %d", room.CurrentRound)
-		return
+		h.sendError(client, ErrCodeRoundNotActive, "Opponent has not selected their pins")
+		return nil
 	}
 	targetPin := opponent.Pins[room.CurrentRound-1]
 
+	// Count the attempt before anything else can end the round
+	round := room.CurrentRound
+	h.gameLogic.RecordAttempt(room, playerID, time.Now())
+
 	// 4. Generate Hints
-	hints := h.gameLogic.GenerateHints(payload.Guess, targetPin)
+	strategy := h.gameLogic.HintStrategy(room.Config)
+	hints := strategy.Hints(payload.Guess, targetPin)
+
+	// 5. Check Win, or whether anybody can still guess
+	var announce func()
+	playerLeft := h.gameLogic.GuessesLeft(room, playerID)
+	opponentLeft := h.gameLogic.GuessesLeft(room, opponentID)
+	switch {
+	case h.gameLogic.IsWin(payload.Guess, targetPin):
+		// Calculate Score
+		if room.Scores == nil {
+			room.Scores = make(map[string]int)
+		}
+		room.Scores[playerID]++
+
+		// End Round
+		announce = h.closeRound(room, playerID, RoundEndGuessed)
+	case IsSolo(room.Config) && playerLeft == 0:
+		// Running out of guesses loses the round, like running out of time
+		if room.Scores == nil {
+			room.Scores = make(map[string]int)
+		}
+		room.Scores[opponentID]++
+		announce = h.closeRound(room, opponentID, RoundEndOutOfGuesses)
+	case playerLeft == 0 && opponentLeft == 0:
+		// End the round once nobody can guess any more
+		announce = h.closeRound(room, "", RoundEndOutOfGuesses)
+	case room.Config.TurnBased && opponentLeft != 0:
+		room.CurrentTurn = opponentID
+		announce = func() { h.announceTurn(room, opponentID, TurnReasonGuess) }
+	}
+
+	// 6. Save, so that only one update of the room wins a race
+	if err := h.store.SaveRoom(ctx, room); err != nil {
+		return err
+	}
 
-	// 5. Broadcast Result
+	// 7. Broadcast Result
 	response := GameMessage{
 		Type: "guess_result",
 		Payload: map[string]interface{}{
 			"room_id":   payload.RoomID,
 			"player_id": playerID,
 			"guess":     payload.Guess,
+			"hint_mode": strategy.Mode(),
 			"hints":     hints,
 		},
 	}
 
-	responseBytes, _ := json.Marshal(response)
-	h.Broadcast <- responseBytes
+	h.BroadcastToRoom(payload.RoomID, response)
 
-	// 6. Check Win
-	if h.gameLogic.IsWin(payload.Guess, targetPin) {
-		// Calculate Score
-		if room.Scores == nil {
-			room.Scores = make(map[string]int)
-		}
-		room.Scores[playerID]++
+	// Keep a history of the round so it can be shown again later
+	record := &store.GuessRecord{
+		PlayerID:  playerID,
+		Guess:     payload.Guess,
+		HintMode:  strategy.Mode(),
+		Hints:     hints,
+		CreatedAt: time.Now(),
+	}
+	if err := h.store.AppendGuess(ctx, room.ID, round, record); err != nil {
+		log.Printf("Error saving guess: %v", err)
+	}
 
-		// End Round
-		h.handleRoundEnd(room, playerID)
+	if announce != nil {
+		announce()
 	}
+	return nil
+}
+
+// Reasons sent in round_end messages
+const (
+	RoundEndGuessed      = "guessed"        // A player guessed the pin
+	RoundEndTimeout      = "timeout"        // The round timer ran out
+	RoundEndOutOfGuesses = "out_of_guesses" // Every player used up max_guesses
+)
+
+// Reasons sent in game_end messages
+const (
+	GameEndCompleted = "completed" // The rounds were played out or the result could no longer change
+	GameEndForfeit   = "forfeit"   // A player stayed disconnected past the grace period
+)
+
+// roundTimer cancels a timer started for one round of a room
+type roundTimer struct {
+	round  int
+	cancel context.CancelFunc
 }
 
-func (h *Hub) handleRoundEnd(room *store.Room, winnerID string) {
-	// Cancel timer for this room
+// stopRoomTimers cancels the round and turn timers for the room
+func (h *Hub) stopRoomTimers(roomID string) {
 	h.mu.Lock()
-	if cancel, ok := h.timers[room.ID]; ok {
-		cancel()
-		delete(h.timers, room.ID)
+	defer h.mu.Unlock()
+	if timer, ok := h.timers[roomID]; ok {
+		timer.cancel()
+		delete(h.timers, roomID)
 	}
-	h.mu.Unlock()
+	h.stopTurnTimerLocked(roomID)
+}
 
-	ctx := context.Background()
+// stopRoundTimers cancels the room's round and turn timers if they belong to the given round.
+// Once a round is saved as closed, the next one can open before its end is announced,
+// and the timers of the new round must keep running.
+func (h *Hub) stopRoundTimers(roomID string, round int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if timer, ok := h.timers[roomID]; ok && timer.round == round {
+		timer.cancel()
+		delete(h.timers, roomID)
+	}
+	if timer, ok := h.turnTimers[roomID]; ok && timer.round == round {
+		timer.cancel()
+		delete(h.turnTimers, roomID)
+	}
+}
+
+// handleRoundEnd ends the current round of the room, saves it and announces the result.
+// Nothing is announced if the room could not be saved.
+func (h *Hub) handleRoundEnd(room *store.Room, winnerID, reason string) error {
+	announce := h.closeRound(room, winnerID, reason)
+	if err := h.store.SaveRoom(context.Background(), room); err != nil {
+		return err
+	}
+	announce()
+	return nil
+}
+
+// closeRound ends the current round of the room and, if that decides it, the game.
+// It only changes room: the returned function announces the result once room has been saved.
+func (h *Hub) closeRound(room *store.Room, winnerID, reason string) func() {
+	// Close the round so no further guesses are accepted
+	room.RoundActive = false
+	room.CurrentTurn = ""
+
+	breakdown := room.RoundStats
+	if breakdown == nil {
+		breakdown = make(map[string]*store.PlayerStats)
+	}
+	h.gameLogic.AddRoundStats(room, winnerID)
 
 	// Prepare Round End Message
 	msg := GameMessage{
@@ -339,35 +666,62 @@ func (h *Hub) handleRoundEnd(room *store.Room, winnerID string) {
 			"winner_id": winnerID,
 			"round":     room.CurrentRound,
 			"scores":    room.Scores,
+			"reason":    reason,
+			"breakdown": breakdown,
+			"pins":      h.roundPins(room),
 		},
 	}
 
-	// Broadcast
-	responseBytes, _ := json.Marshal(msg)
-	h.Broadcast <- responseBytes
-
-	// Check for Game End
-	if room.CurrentRound >= 3 {
+	// Check for Game End, either after the final round or once the result can no longer change
+	round := room.CurrentRound
+	announceGame := func() {}
+	totalRounds := room.Config.TotalRounds()
+	if room.CurrentRound >= totalRounds || h.gameLogic.IsDecided(room.Scores, totalRounds-room.CurrentRound) {
 		// Game Over
-		h.handleGameEnd(room)
-		return
+		winnerID, isDraw, tiebreak := h.decideGame(room)
+		announceGame = h.closeGame(room, winnerID, isDraw, tiebreak, GameEndCompleted)
+	} else {
+		// Advance Round. Wait for players to be ready before starting the next round.
+		room.CurrentRound++
 	}
 
-	// Advance Round
-	room.CurrentRound++
-	if err := h.store.SaveRoom(ctx, room); err != nil {
-		log.Printf("Error saving room state: %v", err)
+	return func() {
+		// Cancel the timers of the round that ended
+		h.stopRoundTimers(room.ID, round)
+
+		// Broadcast
+		h.BroadcastToRoom(room.ID, msg)
+		announceGame()
 	}
+}
+
+// roundPins returns the pin each player set for the current round, to reveal them once it has ended
+func (h *Hub) roundPins(room *store.Room) map[string]string {
+	ctx := context.Background()
+	pins := make(map[string]string)
 
-	// Wait for players to be ready before starting the next round
-	// We do NOT send round_start here anymore.
+	players, err := h.store.GetRoomPlayers(ctx, room.ID)
+	if err != nil {
+		log.Printf("Error getting players to reveal pins: %v", err)
+		return pins
+	}
+	for _, pid := range players {
+		player, err := h.store.GetPlayer(ctx, pid)
+		if err != nil {
+			log.Printf("Error getting player to reveal pins: %v", err)
+			continue
+		}
+		if room.CurrentRound >= 1 && len(player.Pins) >= room.CurrentRound {
+			pins[pid] = player.Pins[room.CurrentRound-1]
+		}
+	}
+	return pins
 }
 
-func (h *Hub) handleGameEnd(room *store.Room) {
-	// Determine Overall Winner
-	var winnerID string
-	maxScore := -1
-	isDraw := false
+// decideGame works out the overall winner of the room
+func (h *Hub) decideGame(room *store.Room) (winnerID string, isDraw bool, tiebreak string) {
+	maxScore := 0
+	isDraw = true // Until a player has won a round
 
 	for pid, score := range room.Scores {
 		if score > maxScore {
@@ -379,24 +733,46 @@ func (h *Hub) handleGameEnd(room *store.Room) {
 		}
 	}
 
-	status := "finished"
 	if isDraw {
 		winnerID = "" // No winner
+		// Separate players level on rounds won by how efficiently they won them. Games where nobody
+		// won a round stay drawn, and the server's player in solo games has no stats to compare.
+		if maxScore > 0 && !IsSolo(room.Config) {
+			if players, err := h.store.GetRoomPlayers(context.Background(), room.ID); err == nil {
+				var tied []string
+				for _, pid := range players {
+					if room.Scores[pid] == maxScore {
+						tied = append(tied, pid)
+					}
+				}
+				winnerID, tiebreak = h.gameLogic.BreakTie(tied, room.SolvedStats)
+				isDraw = winnerID == ""
+			}
+		}
 	}
 
-	room.Status = status
-	h.store.SaveRoom(context.Background(), room)
+	return winnerID, isDraw, tiebreak
+}
 
-	msg := GameMessage{
-		Type: "game_end",
-		Payload: map[string]interface{}{
-			"room_id":   room.ID,
-			"winner_id": winnerID,
-			"scores":    room.Scores,
-			"is_draw":   isDraw,
-		},
+// closeGame marks the game as finished. The returned function updates the players' ratings and
+// announces the result once room has been saved, so each game is only rated once.
+func (h *Hub) closeGame(room *store.Room, winnerID string, isDraw bool, tiebreak, reason string) func() {
+	room.Status = "finished"
+
+	payload := map[string]interface{}{
+		"room_id":   room.ID,
+		"winner_id": winnerID,
+		"scores":    room.Scores,
+		"is_draw":   isDraw,
+		"tiebreak":  tiebreak,
+		"stats":     room.GameStats,
+		"reason":    reason,
 	}
 
-	responseBytes, _ := json.Marshal(msg)
-	h.Broadcast <- responseBytes
+	return func() {
+		if ratings := h.updateRatings(room, winnerID, isDraw); len(ratings) > 0 {
+			payload["ratings"] = ratings
+		}
+		h.BroadcastToRoom(room.ID, GameMessage{Type: "game_end", Payload: payload})
+	}
 }
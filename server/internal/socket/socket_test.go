@@ -0,0 +1,634 @@
+package socket
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/obasekietinosa/lockpick-api/internal/auth"
+	"github.com/obasekietinosa/lockpick-api/internal/config"
+	"github.com/obasekietinosa/lockpick-api/internal/store"
+)
+
+func TestHub_BroadcastToRoom(t *testing.T) {
+	hub := NewHub(&config.Config{}, store.NewMemoryStore())
+	go hub.Run()
+
+	inRoom := &Client{Hub: hub, Send: make(chan []byte, 10), RoomID: "room1", PlayerID: "p1"}
+	otherRoom := &Client{Hub: hub, Send: make(chan []byte, 10), RoomID: "room2", PlayerID: "p3"}
+	unsubscribed := &Client{Hub: hub, Send: make(chan []byte, 10)}
+	hub.Register <- inRoom
+	hub.Register <- otherRoom
+	hub.Register <- unsubscribed
+
+	hub.BroadcastToRoom("room1", GameMessage{
+		Type:    "round_start",
+		Payload: map[string]interface{}{"room_id": "room1", "round": 1},
+	})
+
+	select {
+	case msgBytes := <-inRoom.Send:
+		var msg GameMessage
+		if err := json.Unmarshal(msgBytes, &msg); err != nil {
+			t.Fatalf("Failed to unmarshal message: %v", err)
+		}
+		if msg.Type != "round_start" {
+			t.Errorf("Expected round_start, got %s", msg.Type)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for room message")
+	}
+
+	// Give the hub a moment to (incorrectly) deliver to other clients
+	time.Sleep(50 * time.Millisecond)
+
+	if len(otherRoom.Send) != 0 {
+		t.Errorf("Client in another room received %d messages", len(otherRoom.Send))
+	}
+	if len(unsubscribed.Send) != 0 {
+		t.Errorf("Unsubscribed client received %d messages", len(unsubscribed.Send))
+	}
+}
+
+func TestHub_UnregisterRemovesRoomSubscription(t *testing.T) {
+	hub := NewHub(&config.Config{}, store.NewMemoryStore())
+	go hub.Run()
+
+	client := &Client{Hub: hub, Send: make(chan []byte, 10), RoomID: "room1"}
+	hub.Register <- client
+	hub.Unregister <- client
+
+	// Run has finished processing Unregister once it accepts the broadcast
+	hub.BroadcastToRoom("room1", GameMessage{Type: "round_start"})
+
+	if _, ok := <-client.Send; ok {
+		t.Error("Expected send channel to be closed after unregister")
+	}
+}
+
+func TestServeWs_RejectsInvalidToken(t *testing.T) {
+	hub := NewHub(&config.Config{SessionSecret: "secret"}, store.NewMemoryStore())
+	go hub.Run()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ServeWs(hub, w, r)
+	}))
+	defer srv.Close()
+
+	forged := auth.NewSigner([]byte("wrong")).Sign("room1", "p1")
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "?token=" + forged
+
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	if err == nil {
+		t.Fatal("Expected dial with forged token to fail")
+	}
+	if resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %v", resp)
+	}
+}
+
+func TestServeWs_RejectsMismatchedIdentity(t *testing.T) {
+	cfg := &config.Config{SessionSecret: "secret"}
+	hub := NewHub(cfg, store.NewMemoryStore())
+	go hub.Run()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ServeWs(hub, w, r)
+	}))
+	defer srv.Close()
+
+	token := auth.NewSigner([]byte(cfg.SessionSecret)).Sign("room1", "p1")
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "?token=" + token
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	// Guess as another player
+	err = conn.WriteJSON(GameMessage{
+		Type:    "guess",
+		Payload: GuessPayload{RoomID: "room1", PlayerID: "p2", Guess: "123"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to send guess: %v", err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	var msg GameMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("Failed to read reply: %v", err)
+	}
+	if msg.Type != "error" {
+		t.Errorf("Expected error, got %s", msg.Type)
+	}
+}
+
+func TestHub_HandleGuess_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(m *store.MemoryStore)
+		guess string
+		want  ErrorCode
+	}{
+		{
+			name:  "Room Not Found",
+			setup: func(m *store.MemoryStore) {},
+			guess: "1234",
+			want:  ErrCodeRoomNotFound,
+		},
+		{
+			name: "Game Finished",
+			setup: func(m *store.MemoryStore) {
+				m.SaveRoom(nil, &store.Room{ID: "room1", Status: "finished", Config: &store.GameConfig{PinLength: 4}, CurrentRound: 3})
+			},
+			guess: "1234",
+			want:  ErrCodeGameFinished,
+		},
+		{
+			name: "Waiting For Opponent",
+			setup: func(m *store.MemoryStore) {
+				m.SaveRoom(nil, &store.Room{ID: "room1", Status: "waiting", Config: &store.GameConfig{PinLength: 4}, CurrentRound: 1})
+				addPlayer(m, &store.Player{ID: "p1", RoomID: "room1"})
+			},
+			guess: "1234",
+			want:  ErrCodeRoundNotActive,
+		},
+		{
+			name: "Wrong Guess Length",
+			setup: func(m *store.MemoryStore) {
+				m.SaveRoom(nil, &store.Room{ID: "room1", Status: "playing", Config: &store.GameConfig{PinLength: 4}, CurrentRound: 1, RoundActive: true})
+				addPlayer(m, &store.Player{ID: "p1", RoomID: "room1", Pins: []string{"1111", "2222", "3333"}})
+				addPlayer(m, &store.Player{ID: "p2", RoomID: "room1", Pins: []string{"4444", "5555", "6666"}})
+			},
+			guess: "12",
+			want:  ErrCodeInvalidGuess,
+		},
+		{
+			name: "Non-Digit Guess",
+			setup: func(m *store.MemoryStore) {
+				m.SaveRoom(nil, &store.Room{ID: "room1", Status: "playing", Config: &store.GameConfig{PinLength: 4}, CurrentRound: 1, RoundActive: true})
+			},
+			guess: "12a4",
+			want:  ErrCodeInvalidGuess,
+		},
+		{
+			name: "Between Rounds",
+			setup: func(m *store.MemoryStore) {
+				m.SaveRoom(nil, &store.Room{ID: "room1", Status: "playing", Config: &store.GameConfig{PinLength: 4}, CurrentRound: 2})
+				addPlayer(m, &store.Player{ID: "p1", RoomID: "room1", Pins: []string{"1111", "2222", "3333"}})
+				addPlayer(m, &store.Player{ID: "p2", RoomID: "room1", Pins: []string{"4444", "5555", "6666"}})
+			},
+			guess: "5555",
+			want:  ErrCodeRoundNotActive,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memStore := store.NewMemoryStore()
+			tt.setup(memStore)
+			hub := NewHub(&config.Config{}, memStore)
+			go hub.Run()
+
+			client := &Client{Hub: hub, Send: make(chan []byte, 10), RoomID: "room1", PlayerID: "p1"}
+			opponent := &Client{Hub: hub, Send: make(chan []byte, 10), RoomID: "room1", PlayerID: "p2"}
+			hub.Register <- client
+			hub.Register <- opponent
+
+			hub.HandleMessage(client, GameMessage{Type: "guess", Payload: map[string]interface{}{"guess": tt.guess}})
+
+			msg := readMessage(t, client)
+			if msg.Type != "error" {
+				t.Fatalf("Expected error, got %s", msg.Type)
+			}
+			if code := msg.Payload.(map[string]interface{})["code"]; code != string(tt.want) {
+				t.Errorf("Expected code %s, got %v", tt.want, code)
+			}
+			if len(opponent.Send) != 0 {
+				t.Errorf("Opponent received %d messages", len(opponent.Send))
+			}
+		})
+	}
+}
+
+func TestHub_HandlePlayerReady_RoundInProgress(t *testing.T) {
+	memStore := store.NewMemoryStore()
+	hub := NewHub(&config.Config{}, memStore)
+	go hub.Run()
+
+	memStore.SaveRoom(nil, &store.Room{ID: "room1", Status: "playing", Config: &store.GameConfig{}, CurrentRound: 1, RoundActive: true})
+
+	client := &Client{Hub: hub, Send: make(chan []byte, 10), RoomID: "room1", PlayerID: "p1"}
+	hub.Register <- client
+
+	hub.HandleMessage(client, GameMessage{Type: "player_ready", Payload: map[string]interface{}{}})
+
+	msg := readMessage(t, client)
+	if code := msg.Payload.(map[string]interface{})["code"]; msg.Type != "error" || code != string(ErrCodeRoundInProgress) {
+		t.Errorf("Expected %s error, got %s %v", ErrCodeRoundInProgress, msg.Type, code)
+	}
+}
+
+func TestHub_HandleGuess_ClosesRoundOnWin(t *testing.T) {
+	memStore := store.NewMemoryStore()
+	hub := NewHub(&config.Config{}, memStore)
+	go hub.Run()
+
+	memStore.SaveRoom(nil, &store.Room{ID: "room1", Status: "playing", Config: &store.GameConfig{PinLength: 4}, CurrentRound: 1, RoundActive: true})
+	addPlayer(memStore, &store.Player{ID: "p1", RoomID: "room1", Pins: []string{"1111", "2222", "3333"}})
+	addPlayer(memStore, &store.Player{ID: "p2", RoomID: "room1", Pins: []string{"4444", "5555", "6666"}})
+
+	client := &Client{Hub: hub, Send: make(chan []byte, 10), RoomID: "room1", PlayerID: "p1"}
+	hub.Register <- client
+
+	hub.HandleMessage(client, GameMessage{Type: "guess", Payload: map[string]interface{}{"guess": "4444"}})
+
+	if msg := readMessage(t, client); msg.Type != "guess_result" {
+		t.Fatalf("Expected guess_result, got %s", msg.Type)
+	}
+	msg := readMessage(t, client)
+	if msg.Type != "round_end" {
+		t.Fatalf("Expected round_end, got %s", msg.Type)
+	}
+
+	// Both pins of the finished round are revealed, and only those
+	pins := msg.Payload.(map[string]interface{})["pins"]
+	if want := map[string]interface{}{"p1": "1111", "p2": "4444"}; !reflect.DeepEqual(pins, want) {
+		t.Errorf("Expected revealed pins %v, got %v", want, pins)
+	}
+
+	// A second winning guess must not score again before the next round starts
+	hub.HandleMessage(client, GameMessage{Type: "guess", Payload: map[string]interface{}{"guess": "5555"}})
+
+	msg = readMessage(t, client)
+	if code := msg.Payload.(map[string]interface{})["code"]; msg.Type != "error" || code != string(ErrCodeRoundNotActive) {
+		t.Errorf("Expected %s error, got %s %v", ErrCodeRoundNotActive, msg.Type, code)
+	}
+
+	room, _ := memStore.GetRoom(nil, "room1")
+	if room.Scores["p1"] != 1 {
+		t.Errorf("Expected score 1, got %d", room.Scores["p1"])
+	}
+
+	// Only the accepted guess is kept in the round's history
+	guesses, _ := memStore.ListGuesses(nil, "room1", 1)
+	if len(guesses) != 1 || guesses[0].Guess != "4444" || guesses[0].PlayerID != "p1" {
+		t.Errorf("Expected the winning guess to be recorded, got %+v", guesses)
+	}
+}
+
+func TestHub_RoundEndsOnce(t *testing.T) {
+	memStore := store.NewMemoryStore()
+	hub := NewHub(&config.Config{}, memStore)
+	go hub.Run()
+
+	memStore.SaveRoom(nil, &store.Room{ID: "room1", Status: "playing", Config: &store.GameConfig{PinLength: 4, Rounds: 1}, CurrentRound: 1, RoundActive: true})
+	addPlayer(memStore, &store.Player{ID: "p1", RoomID: "room1", Pins: []string{"1111"}})
+	addPlayer(memStore, &store.Player{ID: "p2", RoomID: "room1", Pins: []string{"4444"}})
+
+	client := &Client{Hub: hub, Send: make(chan []byte, 10), RoomID: "room1", PlayerID: "p1"}
+	hub.Register <- client
+
+	// Read by a writer that has not seen the winning guess yet
+	stale, _ := memStore.GetRoom(nil, "room1")
+
+	hub.HandleMessage(client, GameMessage{Type: "guess", Payload: map[string]interface{}{"guess": "4444"}})
+
+	for _, want := range []string{"guess_result", "round_end", "game_end"} {
+		if msg := readMessage(t, client); msg.Type != want {
+			t.Fatalf("Expected %s, got %s", want, msg.Type)
+		}
+	}
+
+	// The round timer firing late must not end the round again
+	hub.handleRoundTimeout("room1", 1)
+
+	select {
+	case msgBytes := <-client.Send:
+		t.Errorf("Expected no more messages, got %s", msgBytes)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	stale.RoundActive = false
+	if err := memStore.SaveRoom(nil, stale); err != store.ErrConflict {
+		t.Errorf("Expected a stale save to conflict, got %v", err)
+	}
+
+	room, _ := memStore.GetRoom(nil, "room1")
+	if room.Status != "finished" || room.Scores["p1"] != 1 {
+		t.Errorf("Expected p1 to win the finished game, got %s %v", room.Status, room.Scores)
+	}
+}
+
+func TestHub_RoundEndsOnce_Concurrent(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		memStore := store.NewMemoryStore()
+		hub := NewHub(&config.Config{}, memStore)
+		go hub.Run()
+
+		memStore.SaveRoom(nil, &store.Room{ID: "room1", Status: "playing", Config: &store.GameConfig{PinLength: 4}, CurrentRound: 1, RoundActive: true})
+		addPlayer(memStore, &store.Player{ID: "p1", RoomID: "room1", Pins: []string{"1111", "2222", "3333"}})
+		addPlayer(memStore, &store.Player{ID: "p2", RoomID: "room1", Pins: []string{"4444", "5555", "6666"}})
+
+		client := &Client{Hub: hub, Send: make(chan []byte, 10), RoomID: "room1", PlayerID: "p1"}
+		hub.Register <- client
+
+		// The winning guess and the round timer race to close the round
+		start := make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			<-start
+			hub.HandleMessage(client, GameMessage{Type: "guess", Payload: map[string]interface{}{"guess": "4444"}})
+		}()
+		go func() {
+			defer wg.Done()
+			<-start
+			hub.handleRoundTimeout("room1", 1)
+		}()
+		close(start)
+		wg.Wait()
+
+		roundEnds := 0
+		for done := false; !done; {
+			select {
+			case msgBytes := <-client.Send:
+				var msg GameMessage
+				json.Unmarshal(msgBytes, &msg)
+				if msg.Type == "round_end" {
+					roundEnds++
+				}
+			case <-time.After(100 * time.Millisecond):
+				done = true
+			}
+		}
+		if roundEnds != 1 {
+			t.Fatalf("Expected exactly one round_end, got %d", roundEnds)
+		}
+
+		room, _ := memStore.GetRoom(nil, "room1")
+		if room.CurrentRound != 2 || room.RoundActive {
+			t.Fatalf("Expected the round to close once and advance to round 2, got round %d active %v", room.CurrentRound, room.RoundActive)
+		}
+	}
+}
+
+func TestHub_RoundEnd_KeepsNextRoundTimer(t *testing.T) {
+	memStore := store.NewMemoryStore()
+	hub := NewHub(&config.Config{}, memStore)
+	go hub.Run()
+
+	memStore.SaveRoom(nil, &store.Room{ID: "room1", Status: "playing", Config: &store.GameConfig{PinLength: 4, TimerDuration: 60}, CurrentRound: 1, RoundActive: true})
+	addPlayer(memStore, &store.Player{ID: "p1", RoomID: "room1", Pins: []string{"1111", "2222", "3333"}})
+	addPlayer(memStore, &store.Player{ID: "p2", RoomID: "room1", Pins: []string{"4444", "5555", "6666"}})
+	hub.StartRound("room1")
+	defer hub.stopRoomTimers("room1")
+
+	room, _ := memStore.GetRoom(nil, "room1")
+	announce := hub.closeRound(room, "", RoundEndTimeout)
+	if err := memStore.SaveRoom(nil, room); err != nil {
+		t.Fatalf("SaveRoom: %v", err)
+	}
+
+	// Both players ready up for round 2 before the end of round 1 is announced
+	room, _ = memStore.GetRoom(nil, "room1")
+	OpenRound(room, time.Now())
+	if err := memStore.SaveRoom(nil, room); err != nil {
+		t.Fatalf("SaveRoom: %v", err)
+	}
+	hub.StartRound("room1")
+
+	announce()
+
+	hub.mu.Lock()
+	timer, ok := hub.timers["room1"]
+	hub.mu.Unlock()
+	if !ok || timer.round != 2 {
+		t.Errorf("Expected the round 2 timer to keep running, got %+v, %v", timer, ok)
+	}
+}
+
+func TestHub_HandleGuess_EndsGameWhenClinched(t *testing.T) {
+	tests := []struct {
+		name         string
+		rounds       int
+		currentRound int
+		scores       map[string]int
+		wantGameEnd  bool
+	}{
+		{name: "Best Of 1", rounds: 1, currentRound: 1, wantGameEnd: true},
+		{name: "Best Of 5 Clinched In Round 3", rounds: 5, currentRound: 3, scores: map[string]int{"p1": 2}, wantGameEnd: true},
+		{name: "Best Of 5 Still Open", rounds: 5, currentRound: 3, scores: map[string]int{"p1": 1, "p2": 1}, wantGameEnd: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memStore := store.NewMemoryStore()
+			hub := NewHub(&config.Config{}, memStore)
+			go hub.Run()
+
+			pins := []string{"4444", "5555", "6666", "7777", "8888"}[:tt.rounds]
+			memStore.SaveRoom(nil, &store.Room{
+				ID:           "room1",
+				Status:       "playing",
+				Config:       &store.GameConfig{PinLength: 4, Rounds: tt.rounds},
+				CurrentRound: tt.currentRound,
+				Scores:       tt.scores,
+				RoundActive:  true,
+			})
+			addPlayer(memStore, &store.Player{ID: "p1", RoomID: "room1", Pins: pins})
+			addPlayer(memStore, &store.Player{ID: "p2", RoomID: "room1", Pins: pins})
+
+			client := &Client{Hub: hub, Send: make(chan []byte, 10), RoomID: "room1", PlayerID: "p1"}
+			hub.Register <- client
+
+			hub.HandleMessage(client, GameMessage{Type: "guess", Payload: map[string]interface{}{"guess": pins[tt.currentRound-1]}})
+
+			for _, want := range []string{"guess_result", "round_end"} {
+				if msg := readMessage(t, client); msg.Type != want {
+					t.Fatalf("Expected %s, got %s", want, msg.Type)
+				}
+			}
+
+			room, _ := memStore.GetRoom(nil, "room1")
+			if tt.wantGameEnd {
+				if msg := readMessage(t, client); msg.Type != "game_end" {
+					t.Errorf("Expected game_end, got %s", msg.Type)
+				}
+				if room.Status != "finished" {
+					t.Errorf("Expected room to be finished, got %s", room.Status)
+				}
+			} else if room.CurrentRound != tt.currentRound+1 {
+				t.Errorf("Expected round to advance to %d, got %d", tt.currentRound+1, room.CurrentRound)
+			}
+		})
+	}
+}
+
+func TestHub_HandleGuess_MaxGuesses(t *testing.T) {
+	memStore := store.NewMemoryStore()
+	hub := NewHub(&config.Config{}, memStore)
+	go hub.Run()
+
+	memStore.SaveRoom(nil, &store.Room{ID: "room1", Status: "playing", Config: &store.GameConfig{PinLength: 4, MaxGuesses: 1}, CurrentRound: 1, RoundActive: true})
+	addPlayer(memStore, &store.Player{ID: "p1", RoomID: "room1", Pins: []string{"1111", "2222", "3333"}})
+	addPlayer(memStore, &store.Player{ID: "p2", RoomID: "room1", Pins: []string{"4444", "5555", "6666"}})
+
+	client1 := &Client{Hub: hub, Send: make(chan []byte, 10), RoomID: "room1", PlayerID: "p1"}
+	client2 := &Client{Hub: hub, Send: make(chan []byte, 10), RoomID: "room1", PlayerID: "p2"}
+	hub.Register <- client1
+	hub.Register <- client2
+
+	hub.HandleMessage(client1, GameMessage{Type: "guess", Payload: map[string]interface{}{"guess": "1234"}})
+	if msg := readMessage(t, client1); msg.Type != "guess_result" {
+		t.Fatalf("Expected guess_result, got %s", msg.Type)
+	}
+
+	// p1 has used their only guess
+	hub.HandleMessage(client1, GameMessage{Type: "guess", Payload: map[string]interface{}{"guess": "4444"}})
+	msg := readMessage(t, client1)
+	if code := msg.Payload.(map[string]interface{})["code"]; msg.Type != "error" || code != string(ErrCodeNoGuessesLeft) {
+		t.Fatalf("Expected %s error, got %s %v", ErrCodeNoGuessesLeft, msg.Type, code)
+	}
+
+	// Once p2 also runs out, the round ends without a winner
+	hub.HandleMessage(client2, GameMessage{Type: "guess", Payload: map[string]interface{}{"guess": "1234"}})
+	if msg := readMessage(t, client1); msg.Type != "guess_result" {
+		t.Fatalf("Expected guess_result, got %s", msg.Type)
+	}
+	msg = readMessage(t, client1)
+	if msg.Type != "round_end" {
+		t.Fatalf("Expected round_end, got %s", msg.Type)
+	}
+	payload := msg.Payload.(map[string]interface{})
+	if payload["winner_id"] != "" || payload["reason"] != RoundEndOutOfGuesses {
+		t.Errorf("Expected a draw out of guesses, got %v", payload)
+	}
+	breakdown := payload["breakdown"].(map[string]interface{})
+	for _, pid := range []string{"p1", "p2"} {
+		stats := breakdown[pid].(map[string]interface{})
+		if int(stats["attempts"].(float64)) != 1 {
+			t.Errorf("Expected 1 attempt for %s, got %v", pid, stats["attempts"])
+		}
+	}
+
+	room, _ := memStore.GetRoom(nil, "room1")
+	if room.GameStats["p1"].Attempts != 1 || room.RoundStats != nil {
+		t.Errorf("Expected round stats to be added to game totals, got %+v", room.GameStats)
+	}
+}
+
+func TestHub_DecideGame(t *testing.T) {
+	memStore := store.NewMemoryStore()
+	hub := NewHub(&config.Config{}, memStore)
+	addPlayer(memStore, &store.Player{ID: "p1", RoomID: "room1"})
+	addPlayer(memStore, &store.Player{ID: "p2", RoomID: "room1"})
+
+	tests := []struct {
+		name         string
+		room         *store.Room
+		wantWinner   string
+		wantDraw     bool
+		wantTiebreak string
+	}{
+		{
+			name:       "More Rounds Won",
+			room:       &store.Room{Scores: map[string]int{"p1": 2, "p2": 1}},
+			wantWinner: "p1",
+		},
+		{
+			// p2 stopped guessing, which must not win them the game
+			name: "Nobody Won A Round",
+			room: &store.Room{
+				Scores:    map[string]int{},
+				GameStats: map[string]*store.PlayerStats{"p1": {Attempts: 9, TimeTakenMs: 90000}},
+			},
+			wantDraw: true,
+		},
+		{
+			// p1 guessed more across the game, but solved their round in fewer attempts
+			name: "Level On Rounds Won",
+			room: &store.Room{
+				Scores:      map[string]int{"p1": 1, "p2": 1},
+				GameStats:   map[string]*store.PlayerStats{"p1": {Attempts: 12}, "p2": {Attempts: 5}},
+				SolvedStats: map[string]*store.PlayerStats{"p1": {Attempts: 3}, "p2": {Attempts: 4}},
+			},
+			wantWinner:   "p1",
+			wantTiebreak: TiebreakAttempts,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.room.ID = "room1"
+			tt.room.Config = &store.GameConfig{PinLength: 4}
+			winner, isDraw, tiebreak := hub.decideGame(tt.room)
+			if winner != tt.wantWinner || isDraw != tt.wantDraw || tiebreak != tt.wantTiebreak {
+				t.Errorf("decideGame() = (%q, %v, %q), want (%q, %v, %q)", winner, isDraw, tiebreak, tt.wantWinner, tt.wantDraw, tt.wantTiebreak)
+			}
+		})
+	}
+}
+
+// roundOpeningStore records the room saved when its round was opened
+type roundOpeningStore struct {
+	*store.MemoryStore
+	opened *store.Room
+}
+
+func (s *roundOpeningStore) SaveRoom(ctx context.Context, room *store.Room) error {
+	if err := s.MemoryStore.SaveRoom(ctx, room); err != nil {
+		return err
+	}
+	if room.RoundActive && s.opened == nil {
+		s.opened, _ = s.MemoryStore.GetRoom(ctx, room.ID)
+	}
+	return nil
+}
+
+func TestHub_HandlePlayerReady_OpensRoundWithStats(t *testing.T) {
+	memStore := &roundOpeningStore{MemoryStore: store.NewMemoryStore()}
+	hub := NewHub(&config.Config{}, memStore)
+	go hub.Run()
+
+	memStore.SaveRoom(nil, &store.Room{
+		ID:             "room1",
+		Status:         "playing",
+		Config:         &store.GameConfig{PinLength: 4},
+		CurrentRound:   2,
+		ReadyPlayers:   []string{"p2"},
+		RoundStartedAt: time.Now().Add(-time.Minute),
+		RoundStats:     map[string]*store.PlayerStats{"p1": {Attempts: 3}},
+	})
+	addPlayer(memStore, &store.Player{ID: "p1", RoomID: "room1"})
+	addPlayer(memStore, &store.Player{ID: "p2", RoomID: "room1"})
+
+	client := &Client{Hub: hub, Send: make(chan []byte, 10), RoomID: "room1", PlayerID: "p1"}
+	hub.Register <- client
+
+	before := time.Now()
+	hub.HandleMessage(client, GameMessage{Type: "player_ready", Payload: map[string]interface{}{}})
+
+	if msg := readMessage(t, client); msg.Type != "round_start" {
+		t.Fatalf("Expected round_start, got %s", msg.Type)
+	}
+
+	// A guess accepted as soon as the round is open must count against the new round's stats
+	opened := memStore.opened
+	if opened == nil {
+		t.Fatal("Expected the round to be opened")
+	}
+	if opened.RoundStartedAt.Before(before) || len(opened.RoundStats) != 0 {
+		t.Errorf("Expected the save opening the round to restart its clock and stats, got %v %v", opened.RoundStartedAt, opened.RoundStats)
+	}
+}
@@ -0,0 +1,148 @@
+package socket
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/obasekietinosa/lockpick-api/internal/store"
+)
+
+// SweepStats counts what the sweeper has reclaimed since the hub started
+type SweepStats struct {
+	Runs        int64     `json:"runs"`
+	RoundTimers int64     `json:"round_timers"` // Round timers for rooms whose round is no longer running
+	TurnTimers  int64     `json:"turn_timers"`  // Turn timers for rooms whose round is no longer running
+	GraceTimers int64     `json:"grace_timers"` // Disconnect grace timers for games that are no longer in progress
+	LastRun     time.Time `json:"last_run"`
+}
+
+// RunSweeper periodically cleans up state left behind by abandoned games until ctx is cancelled.
+func (h *Hub) RunSweeper(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			h.Sweep(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Sweep cancels timers whose game is over or gone.
+// It returns what this run reclaimed.
+func (h *Hub) Sweep(ctx context.Context) SweepStats {
+	run := SweepStats{Runs: 1, LastRun: time.Now()}
+
+	run.RoundTimers = h.sweepTimers(ctx, h.timers, h.isRoundRunning)
+	run.TurnTimers = h.sweepTimers(ctx, h.turnTimers, h.isRoundRunning)
+	run.GraceTimers = h.sweepGraceTimers(ctx)
+
+	if run.RoundTimers+run.TurnTimers+run.GraceTimers > 0 {
+		log.Printf("Sweeper reclaimed %d round timers, %d turn timers, %d grace timers",
+			run.RoundTimers, run.TurnTimers, run.GraceTimers)
+	}
+
+	h.statsMu.Lock()
+	h.sweepStats.Runs += run.Runs
+	h.sweepStats.RoundTimers += run.RoundTimers
+	h.sweepStats.TurnTimers += run.TurnTimers
+	h.sweepStats.GraceTimers += run.GraceTimers
+	h.sweepStats.LastRun = run.LastRun
+	h.statsMu.Unlock()
+
+	return run
+}
+
+// SweepStats returns the totals reclaimed by the sweeper so far
+func (h *Hub) SweepStats() SweepStats {
+	h.statsMu.Lock()
+	defer h.statsMu.Unlock()
+	return h.sweepStats
+}
+
+// isRoundRunning reports whether the room still has a round that its timers belong to
+func (h *Hub) isRoundRunning(ctx context.Context, roomID string) bool {
+	room, err := h.store.GetRoom(ctx, roomID)
+	if errors.Is(err, store.ErrRoomNotFound) {
+		return false
+	}
+	if err != nil {
+		// Keep the timer rather than cancel it over a failed lookup
+		log.Printf("Error getting room %s for sweep: %v", roomID, err)
+		return true
+	}
+	return room.Status == "playing" && room.RoundActive
+}
+
+// isGameRunning reports whether the room has a game in progress
+func (h *Hub) isGameRunning(ctx context.Context, roomID string) bool {
+	room, err := h.store.GetRoom(ctx, roomID)
+	if errors.Is(err, store.ErrRoomNotFound) {
+		return false
+	}
+	if err != nil {
+		// Keep the timer rather than cancel it over a failed lookup
+		log.Printf("Error getting room %s for sweep: %v", roomID, err)
+		return true
+	}
+	return room.Status == "playing"
+}
+
+// sweepTimers cancels the timers in a map guarded by mu whose room fails the live check.
+// mu is held throughout so a round cannot start between the check and the cancel.
+func (h *Hub) sweepTimers(ctx context.Context, timers map[string]roundTimer, live func(context.Context, string) bool) int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var reclaimed int64
+	for roomID, timer := range timers {
+		if !live(ctx, roomID) {
+			timer.cancel()
+			delete(timers, roomID)
+			reclaimed++
+		}
+	}
+	return reclaimed
+}
+
+// sweepGraceTimers cancels grace timers for games that ended while the player was away.
+// Unlike sweepTimers it does not hold its lock during lookups, since Run takes graceMu.
+// A game that is no longer running never needs its grace timer again.
+func (h *Hub) sweepGraceTimers(ctx context.Context) int64 {
+	h.graceMu.Lock()
+	keys := make([]string, 0, len(h.graceTimers))
+	for key := range h.graceTimers {
+		keys = append(keys, key)
+	}
+	h.graceMu.Unlock()
+
+	var orphaned []string
+	for _, key := range keys {
+		roomID, _, _ := strings.Cut(key, ":")
+		if !h.isGameRunning(ctx, roomID) {
+			orphaned = append(orphaned, key)
+		}
+	}
+
+	var reclaimed int64
+	h.graceMu.Lock()
+	defer h.graceMu.Unlock()
+	for _, key := range orphaned {
+		if cancel, ok := h.graceTimers[key]; ok {
+			cancel()
+			delete(h.graceTimers, key)
+			reclaimed++
+		}
+	}
+	return reclaimed
+}
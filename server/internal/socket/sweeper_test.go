@@ -0,0 +1,53 @@
+package socket
+
+import (
+	"context"
+	"testing"
+
+	"github.com/obasekietinosa/lockpick-api/internal/config"
+	"github.com/obasekietinosa/lockpick-api/internal/store"
+)
+
+func TestHub_Sweep(t *testing.T) {
+	memStore := store.NewMemoryStore()
+	hub := NewHub(&config.Config{}, memStore)
+
+	memStore.SaveRoom(nil, &store.Room{ID: "live", Status: "playing", RoundActive: true, Config: &store.GameConfig{}})
+	memStore.SaveRoom(nil, &store.Room{ID: "between", Status: "playing", Config: &store.GameConfig{}})
+	memStore.SaveRoom(nil, &store.Room{ID: "finished", Status: "finished", Config: &store.GameConfig{}})
+
+	cancelled := make(map[string]bool)
+	timer := func(name string) context.CancelFunc {
+		return func() { cancelled[name] = true }
+	}
+	hub.timers["live"] = roundTimer{cancel: timer("round:live")}
+	hub.timers["finished"] = roundTimer{cancel: timer("round:finished")}
+	hub.timers["gone"] = roundTimer{cancel: timer("round:gone")}
+	hub.turnTimers["between"] = roundTimer{cancel: timer("turn:between")}
+	hub.graceTimers[graceKey("live", "p1")] = timer("grace:live")
+	hub.graceTimers[graceKey("finished", "p1")] = timer("grace:finished")
+
+	run := hub.Sweep(context.Background())
+
+	if run.RoundTimers != 2 || run.TurnTimers != 1 || run.GraceTimers != 1 {
+		t.Errorf("Expected 2 round, 1 turn and 1 grace timer reclaimed, got %+v", run)
+	}
+	for _, name := range []string{"round:finished", "round:gone", "turn:between", "grace:finished"} {
+		if !cancelled[name] {
+			t.Errorf("Expected %s to be cancelled", name)
+		}
+	}
+	for _, name := range []string{"round:live", "grace:live"} {
+		if cancelled[name] {
+			t.Errorf("Expected %s to keep running", name)
+		}
+	}
+	if _, ok := hub.timers["live"]; !ok {
+		t.Errorf("Expected live round timer to stay registered")
+	}
+
+	hub.Sweep(context.Background())
+	if stats := hub.SweepStats(); stats.Runs != 2 || stats.RoundTimers != 2 {
+		t.Errorf("Expected totals across 2 runs, got %+v", stats)
+	}
+}
@@ -11,9 +11,9 @@ import (
 
 func TestHub_StartRoundTimer_RoundEnd(t *testing.T) {
 	// Setup
-	mockStore := NewMockStore()
+	memStore := store.NewMemoryStore()
 	cfg := &config.Config{}
-	hub := NewHub(cfg, mockStore)
+	hub := NewHub(cfg, memStore)
 
 	go hub.Run()
 
@@ -23,12 +23,13 @@ func TestHub_StartRoundTimer_RoundEnd(t *testing.T) {
 		Config:       &store.GameConfig{TimerDuration: 1}, // 1 second timer
 		CurrentRound: 1,
 	}
-	mockStore.SaveRoom(nil, room)
+	memStore.SaveRoom(nil, room)
 
 	// Create a client to listen
 	client := &Client{
-		Hub:  hub,
-		Send: make(chan []byte, 10),
+		Hub:    hub,
+		Send:   make(chan []byte, 10),
+		RoomID: roomID,
 	}
 	hub.Register <- client
 
@@ -61,7 +62,7 @@ func TestHub_StartRoundTimer_RoundEnd(t *testing.T) {
 	}
 
 	// Verify room round incremented
-	updatedRoom, _ := mockStore.GetRoom(nil, roomID)
+	updatedRoom, _ := memStore.GetRoom(nil, roomID)
 	if updatedRoom.CurrentRound != 2 {
 		t.Errorf("Expected room round to be 2, got %d", updatedRoom.CurrentRound)
 	}
@@ -69,9 +70,9 @@ func TestHub_StartRoundTimer_RoundEnd(t *testing.T) {
 
 func TestHub_Round0_Bug(t *testing.T) {
 	// Setup
-	mockStore := NewMockStore()
+	memStore := store.NewMemoryStore()
 	cfg := &config.Config{}
-	hub := NewHub(cfg, mockStore)
+	hub := NewHub(cfg, memStore)
 
 	go hub.Run()
 
@@ -81,9 +82,9 @@ func TestHub_Round0_Bug(t *testing.T) {
 		Config:       &store.GameConfig{TimerDuration: 1}, // 1 second timer
 		CurrentRound: 0, // Uninitialized
 	}
-	mockStore.SaveRoom(nil, room)
+	memStore.SaveRoom(nil, room)
 
-	client := &Client{Hub: hub, Send: make(chan []byte, 10)}
+	client := &Client{Hub: hub, Send: make(chan []byte, 10), RoomID: roomID}
 	hub.Register <- client
 	time.Sleep(50 * time.Millisecond)
 
@@ -108,10 +109,63 @@ func TestHub_Round0_Bug(t *testing.T) {
 		t.Fatal("Timeout")
 	}
 
-	updatedRoom, _ := mockStore.GetRoom(nil, roomID)
+	updatedRoom, _ := memStore.GetRoom(nil, roomID)
 	if updatedRoom.CurrentRound == 1 {
 		t.Log("Room round incremented to 1.")
 	} else {
 		t.Errorf("Room round is %d", updatedRoom.CurrentRound)
 	}
 }
+
+func TestHub_SoloTimeout_CountsAsLoss(t *testing.T) {
+	memStore := store.NewMemoryStore()
+	hub := NewHub(&config.Config{}, memStore)
+
+	go hub.Run()
+
+	roomID := "solo1"
+	memStore.SaveRoom(nil, &store.Room{
+		ID:           roomID,
+		HostID:       "p1",
+		Status:       "playing",
+		Config:       &store.GameConfig{TimerDuration: 1, PinLength: 4, Mode: GameModeSolo},
+		CurrentRound: 1,
+		RoundActive:  true,
+	})
+	addPlayer(memStore, &store.Player{ID: "p1", RoomID: roomID})
+	addPlayer(memStore, &store.Player{ID: "house", RoomID: roomID, Pins: []string{"1111", "2222", "3333"}})
+
+	client := &Client{Hub: hub, Send: make(chan []byte, 10), RoomID: roomID, PlayerID: "p1"}
+	hub.Register <- client
+
+	hub.StartRoundTimer(roomID)
+
+	select {
+	case msgBytes := <-client.Send:
+		var msg GameMessage
+		json.Unmarshal(msgBytes, &msg)
+		if msg.Type != "round_end" {
+			t.Fatalf("Expected round_end, got %s", msg.Type)
+		}
+		payload := msg.Payload.(map[string]interface{})
+		if payload["winner_id"] != "house" {
+			t.Errorf("Expected house to win the round, got %v", payload["winner_id"])
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for round_end message")
+	}
+
+	// Only the human player needs to be ready for the next round
+	hub.HandleMessage(client, GameMessage{Type: "player_ready", Payload: map[string]interface{}{}})
+
+	select {
+	case msgBytes := <-client.Send:
+		var msg GameMessage
+		json.Unmarshal(msgBytes, &msg)
+		if msg.Type != "round_start" {
+			t.Errorf("Expected round_start, got %s", msg.Type)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for round_start message")
+	}
+}
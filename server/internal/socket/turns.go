@@ -0,0 +1,187 @@
+package socket
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/obasekietinosa/lockpick-api/internal/store"
+)
+
+// Reasons sent in turn_change messages
+const (
+	TurnReasonRoundStart = "round_start"
+	TurnReasonGuess      = "guess"
+	TurnReasonTimeout    = "timeout"
+)
+
+// StartRound starts the round timer and, in turn-based games, hands the first turn to a player.
+func (h *Hub) StartRound(roomID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.startRoundLocked(roomID)
+}
+
+// startRoundLocked runs the round opened with OpenRound
+func (h *Hub) startRoundLocked(roomID string) {
+	h.startRoundTimerLocked(roomID)
+	h.startFirstTurnLocked(roomID)
+}
+
+// startFirstTurnLocked gives the first turn of the round to the host in odd rounds
+// and to their opponent in even rounds, so neither player always goes first.
+func (h *Hub) startFirstTurnLocked(roomID string) {
+	err := store.RetryOnConflict(func() error {
+		ctx := context.Background()
+		room, err := h.store.GetRoom(ctx, roomID)
+		if err != nil {
+			log.Printf("Error getting room for turns: %v", err)
+			return nil
+		}
+
+		if !room.Config.TurnBased {
+			return nil
+		}
+
+		players, err := h.store.GetRoomPlayers(ctx, roomID)
+		if err != nil {
+			log.Printf("Error getting players for turns: %v", err)
+			return nil
+		}
+
+		first := room.HostID
+		if room.CurrentRound%2 == 0 {
+			for _, pid := range players {
+				if pid != room.HostID {
+					first = pid
+				}
+			}
+		}
+
+		return h.setTurnLocked(room, first, TurnReasonRoundStart)
+	})
+	if err != nil {
+		log.Printf("Error saving first turn: %v", err)
+	}
+}
+
+// passTurn hands the turn to the next player after a turn timeout
+func (h *Hub) passTurn(room *store.Room, nextPlayerID, reason string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.setTurnLocked(room, nextPlayerID, reason)
+}
+
+// setTurnLocked saves the turn and announces it. Nothing is announced if room could not be saved.
+func (h *Hub) setTurnLocked(room *store.Room, playerID, reason string) error {
+	room.CurrentTurn = playerID
+	if err := h.store.SaveRoom(context.Background(), room); err != nil {
+		return err
+	}
+
+	h.announceTurnLocked(room, playerID, reason)
+	return nil
+}
+
+// announceTurn tells the room whose turn it is and starts their turn timer, once the turn has been saved
+func (h *Hub) announceTurn(room *store.Room, playerID, reason string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.announceTurnLocked(room, playerID, reason)
+}
+
+func (h *Hub) announceTurnLocked(room *store.Room, playerID, reason string) {
+	h.BroadcastToRoom(room.ID, GameMessage{
+		Type: "turn_change",
+		Payload: map[string]interface{}{
+			"room_id":   room.ID,
+			"round":     room.CurrentRound,
+			"player_id": playerID,
+			"reason":    reason,
+		},
+	})
+
+	h.startTurnTimerLocked(room, playerID)
+}
+
+// startTurnTimerLocked limits how long the player has to guess, if the game has a turn timer.
+// It runs alongside the round timer: the round can still time out mid-turn.
+func (h *Hub) startTurnTimerLocked(room *store.Room, playerID string) {
+	h.stopTurnTimerLocked(room.ID)
+
+	if room.Config.TurnDuration <= 0 {
+		return
+	}
+
+	timerCtx, cancel := context.WithCancel(context.Background())
+	roomID, round := room.ID, room.CurrentRound
+	h.turnTimers[roomID] = roundTimer{round: round, cancel: cancel}
+
+	go func() {
+		select {
+		case <-time.After(time.Duration(room.Config.TurnDuration) * time.Second):
+			h.handleTurnTimeout(roomID, round, playerID)
+		case <-timerCtx.Done():
+			return
+		}
+	}()
+}
+
+func (h *Hub) stopTurnTimerLocked(roomID string) {
+	if timer, ok := h.turnTimers[roomID]; ok {
+		timer.cancel()
+		delete(h.turnTimers, roomID)
+	}
+}
+
+func (h *Hub) handleTurnTimeout(roomID string, roundNumber int, playerID string) {
+	err := store.RetryOnConflict(func() error {
+		ctx := context.Background()
+		room, err := h.store.GetRoom(ctx, roomID)
+		if err != nil {
+			log.Printf("Error getting room for turn timeout: %v", err)
+			return nil
+		}
+
+		// Check the turn has not moved on (race condition check)
+		if !room.RoundActive || room.CurrentRound != roundNumber || room.CurrentTurn != playerID {
+			return nil
+		}
+
+		opponentID, err := h.opponentID(ctx, room, playerID)
+		if err != nil {
+			log.Printf("Error getting opponent for turn timeout: %v", err)
+			return nil
+		}
+
+		log.Printf("Turn of player %s timed out in room %s", playerID, roomID)
+
+		// The turn skips a player who has used all their guesses, and the round ends once nobody can guess
+		switch {
+		case h.gameLogic.GuessesLeft(room, opponentID) != 0:
+			return h.passTurn(room, opponentID, TurnReasonTimeout)
+		case h.gameLogic.GuessesLeft(room, playerID) != 0:
+			return h.passTurn(room, playerID, TurnReasonTimeout)
+		default:
+			return h.handleRoundEnd(room, "", RoundEndOutOfGuesses)
+		}
+	})
+	if err != nil {
+		log.Printf("Error passing turn in room %s: %v", roomID, err)
+	}
+}
+
+// opponentID returns the other player in a two-player room
+func (h *Hub) opponentID(ctx context.Context, room *store.Room, playerID string) (string, error) {
+	players, err := h.store.GetRoomPlayers(ctx, room.ID)
+	if err != nil {
+		return "", err
+	}
+	for _, pid := range players {
+		if pid != playerID {
+			return pid, nil
+		}
+	}
+	return "", fmt.Errorf("room %s has no opponent for player %s", room.ID, playerID)
+}
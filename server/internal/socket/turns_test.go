@@ -0,0 +1,129 @@
+package socket
+
+import (
+	"testing"
+
+	"github.com/obasekietinosa/lockpick-api/internal/config"
+	"github.com/obasekietinosa/lockpick-api/internal/store"
+)
+
+// newTurnBasedRoom sets up a playing turn-based room hosted by p1 and starts its first round
+func newTurnBasedRoom(t *testing.T, turnDuration int) (*Hub, *store.MemoryStore, *Client, *Client) {
+	t.Helper()
+	memStore := store.NewMemoryStore()
+	hub := NewHub(&config.Config{}, memStore)
+	go hub.Run()
+
+	memStore.SaveRoom(nil, &store.Room{
+		ID:           "room1",
+		HostID:       "p1",
+		Status:       "playing",
+		Config:       &store.GameConfig{PinLength: 4, TurnBased: true, TurnDuration: turnDuration},
+		CurrentRound: 1,
+		RoundActive:  true,
+	})
+	addPlayer(memStore, &store.Player{ID: "p1", RoomID: "room1", Pins: []string{"1111", "2222", "3333"}})
+	addPlayer(memStore, &store.Player{ID: "p2", RoomID: "room1", Pins: []string{"4444", "5555", "6666"}})
+
+	client1 := &Client{Hub: hub, Send: make(chan []byte, 10), RoomID: "room1", PlayerID: "p1"}
+	client2 := &Client{Hub: hub, Send: make(chan []byte, 10), RoomID: "room1", PlayerID: "p2"}
+	hub.Register <- client1
+	hub.Register <- client2
+
+	hub.StartRound("room1")
+
+	msg := readMessage(t, client2)
+	if msg.Type != "turn_change" {
+		t.Fatalf("Expected turn_change, got %s", msg.Type)
+	}
+	if player := msg.Payload.(map[string]interface{})["player_id"]; player != "p1" {
+		t.Fatalf("Expected host to go first, got %v", player)
+	}
+	readMessage(t, client1)
+
+	return hub, memStore, client1, client2
+}
+
+func TestHub_TurnBased_RejectsOutOfTurnGuess(t *testing.T) {
+	hub, _, client1, client2 := newTurnBasedRoom(t, 0)
+
+	hub.HandleMessage(client2, GameMessage{Type: "guess", Payload: map[string]interface{}{"guess": "1111"}})
+
+	msg := readMessage(t, client2)
+	if code := msg.Payload.(map[string]interface{})["code"]; msg.Type != "error" || code != string(ErrCodeNotYourTurn) {
+		t.Errorf("Expected %s error, got %s %v", ErrCodeNotYourTurn, msg.Type, code)
+	}
+	if len(client1.Send) != 0 {
+		t.Errorf("Host received %d messages", len(client1.Send))
+	}
+}
+
+func TestHub_TurnBased_AlternatesAfterGuess(t *testing.T) {
+	hub, memStore, client1, client2 := newTurnBasedRoom(t, 0)
+
+	hub.HandleMessage(client1, GameMessage{Type: "guess", Payload: map[string]interface{}{"guess": "1234"}})
+
+	if msg := readMessage(t, client2); msg.Type != "guess_result" {
+		t.Fatalf("Expected guess_result, got %s", msg.Type)
+	}
+	msg := readMessage(t, client2)
+	if msg.Type != "turn_change" {
+		t.Fatalf("Expected turn_change, got %s", msg.Type)
+	}
+	payload := msg.Payload.(map[string]interface{})
+	if payload["player_id"] != "p2" || payload["reason"] != TurnReasonGuess {
+		t.Errorf("Expected turn to pass to p2 after a guess, got %v", payload)
+	}
+
+	room, _ := memStore.GetRoom(nil, "room1")
+	if room.CurrentTurn != "p2" {
+		t.Errorf("Expected current turn p2, got %s", room.CurrentTurn)
+	}
+}
+
+func TestHub_TurnBased_TurnTimeout(t *testing.T) {
+	_, _, _, client2 := newTurnBasedRoom(t, 1)
+
+	msg := readMessageWithin(t, client2, 2)
+	if msg.Type != "turn_change" {
+		t.Fatalf("Expected turn_change, got %s", msg.Type)
+	}
+	payload := msg.Payload.(map[string]interface{})
+	if payload["player_id"] != "p2" || payload["reason"] != TurnReasonTimeout {
+		t.Errorf("Expected turn to pass to p2 after a timeout, got %v", payload)
+	}
+}
+
+func TestHub_TurnBased_TurnTimeoutSkipsPlayerWithoutGuesses(t *testing.T) {
+	hub, memStore, _, client2 := newTurnBasedRoom(t, 0)
+
+	room, _ := memStore.GetRoom(nil, "room1")
+	room.Config.MaxGuesses = 2
+	room.RoundStats = map[string]*store.PlayerStats{"p1": {Attempts: 1}, "p2": {Attempts: 2}}
+	memStore.SaveRoom(nil, room)
+
+	hub.handleTurnTimeout("room1", 1, "p1")
+
+	msg := readMessage(t, client2)
+	payload := msg.Payload.(map[string]interface{})
+	if msg.Type != "turn_change" || payload["player_id"] != "p1" || payload["reason"] != TurnReasonTimeout {
+		t.Errorf("Expected p1 to keep the turn while p2 has no guesses left, got %s %v", msg.Type, payload)
+	}
+}
+
+func TestHub_TurnBased_TurnTimeoutEndsRoundWithoutGuesses(t *testing.T) {
+	hub, memStore, _, client2 := newTurnBasedRoom(t, 0)
+
+	room, _ := memStore.GetRoom(nil, "room1")
+	room.Config.MaxGuesses = 2
+	room.RoundStats = map[string]*store.PlayerStats{"p1": {Attempts: 2}, "p2": {Attempts: 2}}
+	memStore.SaveRoom(nil, room)
+
+	hub.handleTurnTimeout("room1", 1, "p1")
+
+	msg := readMessage(t, client2)
+	payload := msg.Payload.(map[string]interface{})
+	if msg.Type != "round_end" || payload["reason"] != RoundEndOutOfGuesses {
+		t.Errorf("Expected the round to end when nobody has guesses left, got %s %v", msg.Type, payload)
+	}
+}
@@ -0,0 +1,201 @@
+package store
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownPinKey is returned when pins were sealed with a key that is no longer configured
+var ErrUnknownPinKey = errors.New("pins were encrypted with an unknown key")
+
+// PinKey is an AES-256 key used to encrypt pins at rest. Keys are identified by ID,
+// so pins sealed with an older key can still be opened after rotating to a new one.
+type PinKey struct {
+	ID  string
+	Key []byte
+}
+
+// ParsePinKeys parses a comma separated list of "id:hexkey" pairs, newest key first
+func ParsePinKeys(spec string) ([]PinKey, error) {
+	var keys []PinKey
+	for _, pair := range strings.Split(spec, ",") {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("pin key %q is not in the form id:hexkey", pair)
+		}
+		key, err := hex.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("pin key %s is not hex encoded: %w", id, err)
+		}
+		keys = append(keys, PinKey{ID: id, Key: key})
+	}
+	return keys, nil
+}
+
+// SealedPins holds a player's pins as written by EncryptedStore. The pins are encrypted with a random
+// data key, which is in turn encrypted with the pin key KeyID. Both carry their nonce as a prefix.
+type SealedPins struct {
+	KeyID      string `json:"key_id"`
+	WrappedKey []byte `json:"wrapped_key"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// EncryptedStore encrypts player pins before they reach the Store it wraps, and decrypts them on the way out.
+// New pins are always sealed with the first key. The other keys are only used to open pins sealed before a
+// rotation, which are sealed with the first key again the next time the player is saved.
+type EncryptedStore struct {
+	Store
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+func NewEncryptedStore(inner Store, keys []PinKey) (*EncryptedStore, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("at least one pin key is required")
+	}
+
+	s := &EncryptedStore{Store: inner, current: keys[0].ID, keys: make(map[string]cipher.AEAD)}
+	for _, k := range keys {
+		if len(k.Key) != 32 {
+			return nil, fmt.Errorf("pin key %s must be 32 bytes, got %d", k.ID, len(k.Key))
+		}
+		if _, ok := s.keys[k.ID]; ok {
+			return nil, fmt.Errorf("pin key %s is configured twice", k.ID)
+		}
+		aead, err := newAEAD(k.Key)
+		if err != nil {
+			return nil, err
+		}
+		s.keys[k.ID] = aead
+	}
+	return s, nil
+}
+
+func (s *EncryptedStore) SavePlayer(ctx context.Context, player *Player) error {
+	sealed, err := s.sealPlayer(player)
+	if err != nil {
+		return err
+	}
+	return s.Store.SavePlayer(ctx, sealed)
+}
+
+func (s *EncryptedStore) GetPlayer(ctx context.Context, playerID string) (*Player, error) {
+	player, err := s.Store.GetPlayer(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.openPlayer(player)
+}
+
+func (s *EncryptedStore) JoinRoom(ctx context.Context, roomID string, player *Player, maxPlayers int) error {
+	sealed, err := s.sealPlayer(player)
+	if err != nil {
+		return err
+	}
+	return s.Store.JoinRoom(ctx, roomID, sealed, maxPlayers)
+}
+
+// sealPlayer returns a copy of the player with its pins encrypted
+func (s *EncryptedStore) sealPlayer(player *Player) (*Player, error) {
+	sealed := *player
+	sealed.SealedPins = nil
+	if len(player.Pins) == 0 {
+		return &sealed, nil
+	}
+
+	plaintext, err := json.Marshal(player.Pins)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal pins: %w", err)
+	}
+
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// The player ID is authenticated too, so sealed pins cannot be moved to another player
+	aad := []byte(player.ID)
+	ciphertext, err := seal(dataAEAD, plaintext, aad)
+	if err != nil {
+		return nil, err
+	}
+	wrappedKey, err := seal(s.keys[s.current], dataKey, aad)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed.Pins = nil
+	sealed.SealedPins = &SealedPins{KeyID: s.current, WrappedKey: wrappedKey, Ciphertext: ciphertext}
+	return &sealed, nil
+}
+
+// openPlayer decrypts the pins of a player read from the wrapped store.
+// Players saved before pins were encrypted are returned as they are.
+func (s *EncryptedStore) openPlayer(player *Player) (*Player, error) {
+	if player.SealedPins == nil {
+		return player, nil
+	}
+
+	keyAEAD, ok := s.keys[player.SealedPins.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownPinKey, player.SealedPins.KeyID)
+	}
+
+	aad := []byte(player.ID)
+	dataKey, err := open(keyAEAD, player.SealedPins.WrappedKey, aad)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data key: %w", err)
+	}
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := open(dataAEAD, player.SealedPins.Ciphertext, aad)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt pins: %w", err)
+	}
+
+	var pins []string
+	if err := json.Unmarshal(plaintext, &pins); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal pins: %w", err)
+	}
+	player.Pins = pins
+	player.SealedPins = nil
+	return player, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext and prefixes the result with the random nonce it used
+func seal(aead cipher.AEAD, plaintext, aad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func open(aead cipher.AEAD, sealed, aad []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, aad)
+}
@@ -0,0 +1,163 @@
+package store_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/obasekietinosa/lockpick-api/internal/store"
+	"github.com/obasekietinosa/lockpick-api/internal/store/storetest"
+)
+
+func testPinKey(id string, b byte) store.PinKey {
+	return store.PinKey{ID: id, Key: bytes.Repeat([]byte{b}, 32)}
+}
+
+func newTestEncryptedStore(t *testing.T, inner store.Store, keys ...store.PinKey) *store.EncryptedStore {
+	t.Helper()
+	s, err := store.NewEncryptedStore(inner, keys)
+	if err != nil {
+		t.Fatalf("NewEncryptedStore: %v", err)
+	}
+	return s
+}
+
+func TestEncryptedStore_Contract(t *testing.T) {
+	newStore := func(t *testing.T) store.Store {
+		return newTestEncryptedStore(t, store.NewMemoryStore(), testPinKey("k1", 1))
+	}
+	storetest.Run(t, newStore)
+	storetest.RunConcurrent(t, newStore)
+}
+
+func TestEncryptedStore_PinsAtRest(t *testing.T) {
+	ctx := context.Background()
+	inner := store.NewMemoryStore()
+	s := newTestEncryptedStore(t, inner, testPinKey("k1", 1))
+
+	pins := []string{"12345", "67890"}
+	if err := s.SavePlayer(ctx, &store.Player{ID: "p1", RoomID: "room1", Pins: pins}); err != nil {
+		t.Fatalf("SavePlayer: %v", err)
+	}
+
+	stored, _ := inner.GetPlayer(ctx, "p1")
+	if stored.Pins != nil || stored.SealedPins == nil || stored.SealedPins.KeyID != "k1" {
+		t.Fatalf("Expected pins to be sealed with k1, got %+v", stored)
+	}
+	for _, pin := range pins {
+		if bytes.Contains(stored.SealedPins.Ciphertext, []byte(pin)) {
+			t.Errorf("Pin %s is stored in cleartext", pin)
+		}
+	}
+
+	got, err := s.GetPlayer(ctx, "p1")
+	if err != nil {
+		t.Fatalf("GetPlayer: %v", err)
+	}
+	if !reflect.DeepEqual(got.Pins, pins) || got.SealedPins != nil {
+		t.Errorf("Expected pins %v, got %+v", pins, got)
+	}
+}
+
+func TestEncryptedStore_KeyRotation(t *testing.T) {
+	ctx := context.Background()
+	inner := store.NewMemoryStore()
+
+	old := newTestEncryptedStore(t, inner, testPinKey("k1", 1))
+	if err := old.SavePlayer(ctx, &store.Player{ID: "p1", Pins: []string{"12345"}}); err != nil {
+		t.Fatalf("SavePlayer: %v", err)
+	}
+
+	// After rotating, pins sealed with the old key can still be read and are resealed with the new key
+	rotated := newTestEncryptedStore(t, inner, testPinKey("k2", 2), testPinKey("k1", 1))
+	player, err := rotated.GetPlayer(ctx, "p1")
+	if err != nil {
+		t.Fatalf("GetPlayer after rotation: %v", err)
+	}
+	if err := rotated.SavePlayer(ctx, player); err != nil {
+		t.Fatalf("SavePlayer after rotation: %v", err)
+	}
+	if stored, _ := inner.GetPlayer(ctx, "p1"); stored.SealedPins.KeyID != "k2" {
+		t.Errorf("Expected pins to be resealed with k2, got %s", stored.SealedPins.KeyID)
+	}
+
+	// Once the old key is retired, only the new one is needed
+	retired := newTestEncryptedStore(t, inner, testPinKey("k2", 2))
+	if got, err := retired.GetPlayer(ctx, "p1"); err != nil || got.Pins[0] != "12345" {
+		t.Errorf("Expected pins to be readable with k2, got %+v, %v", got, err)
+	}
+
+	// Pins sealed with a key that is not configured cannot be read
+	if err := old.SavePlayer(ctx, &store.Player{ID: "p2", Pins: []string{"54321"}}); err != nil {
+		t.Fatalf("SavePlayer: %v", err)
+	}
+	if _, err := retired.GetPlayer(ctx, "p2"); !errors.Is(err, store.ErrUnknownPinKey) {
+		t.Errorf("Expected ErrUnknownPinKey, got %v", err)
+	}
+}
+
+func TestEncryptedStore_RejectsMovedPins(t *testing.T) {
+	ctx := context.Background()
+	inner := store.NewMemoryStore()
+	s := newTestEncryptedStore(t, inner, testPinKey("k1", 1))
+
+	s.SavePlayer(ctx, &store.Player{ID: "p1", Pins: []string{"12345"}})
+	stored, _ := inner.GetPlayer(ctx, "p1")
+
+	// Copying another player's sealed pins does not reveal them
+	inner.SavePlayer(ctx, &store.Player{ID: "p2", SealedPins: stored.SealedPins})
+	if _, err := s.GetPlayer(ctx, "p2"); err == nil {
+		t.Error("Expected pins sealed for another player to fail to decrypt")
+	}
+}
+
+func TestParsePinKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		wantIDs []string
+		wantErr bool
+	}{
+		{name: "Single Key", spec: "k1:" + string(bytes.Repeat([]byte("ab"), 32)), wantIDs: []string{"k1"}},
+		{name: "Rotated Keys", spec: "k2:" + string(bytes.Repeat([]byte("cd"), 32)) + ", k1:" + string(bytes.Repeat([]byte("ab"), 32)), wantIDs: []string{"k2", "k1"}},
+		{name: "Missing ID", spec: ":abcd", wantErr: true},
+		{name: "Not Hex", spec: "k1:not-hex", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := store.ParsePinKeys(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePinKeys() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var ids []string
+			for _, k := range keys {
+				ids = append(ids, k.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("Expected key IDs %v, got %v", tt.wantIDs, ids)
+			}
+		})
+	}
+}
+
+func TestNewEncryptedStore_ValidatesKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []store.PinKey
+	}{
+		{name: "No Keys"},
+		{name: "Short Key", keys: []store.PinKey{{ID: "k1", Key: []byte("short")}}},
+		{name: "Duplicate ID", keys: []store.PinKey{testPinKey("k1", 1), testPinKey("k1", 2)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := store.NewEncryptedStore(store.NewMemoryStore(), tt.keys); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
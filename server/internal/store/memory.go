@@ -0,0 +1,224 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// MemoryStore keeps everything in process memory, so the API can run without Redis.
+// Values are copied on the way in and out, like they would be by a real database.
+// Nothing expires, so it is meant for local development and tests.
+type MemoryStore struct {
+	mu          sync.Mutex
+	rooms       map[string][]byte
+	players     map[string][]byte
+	roomPlayers map[string]map[string]bool
+	guesses     map[string][][]byte // "roomID:round" -> guesses
+	profiles    map[string][]byte
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		rooms:       make(map[string][]byte),
+		players:     make(map[string][]byte),
+		roomPlayers: make(map[string]map[string]bool),
+		guesses:     make(map[string][][]byte),
+		profiles:    make(map[string][]byte),
+	}
+}
+
+func (s *MemoryStore) SaveRoom(ctx context.Context, room *Room) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var version int64
+	if stored, err := s.getRoomLocked(room.ID); err == nil {
+		version = stored.Version
+	} else if !errors.Is(err, ErrRoomNotFound) {
+		return err
+	}
+	if room.Version != version {
+		return ErrConflict
+	}
+
+	room.Version++
+	data, err := json.Marshal(room)
+	if err != nil {
+		room.Version--
+		return fmt.Errorf("failed to marshal room: %w", err)
+	}
+	s.rooms[room.ID] = data
+	return nil
+}
+
+func (s *MemoryStore) GetRoom(ctx context.Context, roomID string) (*Room, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.getRoomLocked(roomID)
+}
+
+func (s *MemoryStore) getRoomLocked(roomID string) (*Room, error) {
+	data, ok := s.rooms[roomID]
+	if !ok {
+		return nil, ErrRoomNotFound
+	}
+
+	var room Room
+	if err := json.Unmarshal(data, &room); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal room: %w", err)
+	}
+	return &room, nil
+}
+
+func (s *MemoryStore) SavePlayer(ctx context.Context, player *Player) error {
+	data, err := json.Marshal(player)
+	if err != nil {
+		return fmt.Errorf("failed to marshal player: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.players[player.ID] = data
+	return nil
+}
+
+func (s *MemoryStore) GetPlayer(ctx context.Context, playerID string) (*Player, error) {
+	s.mu.Lock()
+	data, ok := s.players[playerID]
+	s.mu.Unlock()
+	if !ok {
+		return nil, ErrPlayerNotFound
+	}
+
+	var player Player
+	if err := json.Unmarshal(data, &player); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal player: %w", err)
+	}
+	return &player, nil
+}
+
+func (s *MemoryStore) AddPlayerToRoom(ctx context.Context, roomID, playerID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addPlayerLocked(roomID, playerID)
+	return nil
+}
+
+func (s *MemoryStore) addPlayerLocked(roomID, playerID string) {
+	if s.roomPlayers[roomID] == nil {
+		s.roomPlayers[roomID] = make(map[string]bool)
+	}
+	s.roomPlayers[roomID][playerID] = true
+}
+
+func (s *MemoryStore) JoinRoom(ctx context.Context, roomID string, player *Player, maxPlayers int) error {
+	data, err := json.Marshal(player)
+	if err != nil {
+		return fmt.Errorf("failed to marshal player: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	room, err := s.getRoomLocked(roomID)
+	if err != nil {
+		return err
+	}
+	if room.Status != "waiting" {
+		return ErrRoomNotWaiting
+	}
+	members := s.roomPlayers[roomID]
+	if !members[player.ID] && len(members) >= maxPlayers {
+		return ErrRoomFull
+	}
+
+	s.players[player.ID] = data
+	s.addPlayerLocked(roomID, player.ID)
+	return nil
+}
+
+func (s *MemoryStore) GetRoomPlayers(ctx context.Context, roomID string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	players := make([]string, 0, len(s.roomPlayers[roomID]))
+	for playerID := range s.roomPlayers[roomID] {
+		players = append(players, playerID)
+	}
+	return players, nil
+}
+
+func (s *MemoryStore) AppendGuess(ctx context.Context, roomID string, round int, guess *GuessRecord) error {
+	data, err := json.Marshal(guess)
+	if err != nil {
+		return fmt.Errorf("failed to marshal guess: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := fmt.Sprintf("%s:%d", roomID, round)
+	s.guesses[key] = append(s.guesses[key], data)
+	return nil
+}
+
+func (s *MemoryStore) ListGuesses(ctx context.Context, roomID string, round int) ([]*GuessRecord, error) {
+	s.mu.Lock()
+	items := s.guesses[fmt.Sprintf("%s:%d", roomID, round)]
+	s.mu.Unlock()
+
+	guesses := make([]*GuessRecord, 0, len(items))
+	for _, item := range items {
+		var guess GuessRecord
+		if err := json.Unmarshal(item, &guess); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal guess: %w", err)
+		}
+		guesses = append(guesses, &guess)
+	}
+	return guesses, nil
+}
+
+func (s *MemoryStore) SaveProfile(ctx context.Context, profile *Profile) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var version int64
+	if stored, err := s.getProfileLocked(profile.ID); err == nil {
+		version = stored.Version
+	} else if !errors.Is(err, ErrProfileNotFound) {
+		return err
+	}
+	if profile.Version != version {
+		return ErrConflict
+	}
+
+	profile.Version++
+	data, err := json.Marshal(profile)
+	if err != nil {
+		profile.Version--
+		return fmt.Errorf("failed to marshal profile: %w", err)
+	}
+	s.profiles[profile.ID] = data
+	return nil
+}
+
+func (s *MemoryStore) GetProfile(ctx context.Context, profileID string) (*Profile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.getProfileLocked(profileID)
+}
+
+func (s *MemoryStore) getProfileLocked(profileID string) (*Profile, error) {
+	data, ok := s.profiles[profileID]
+	if !ok {
+		return nil, ErrProfileNotFound
+	}
+
+	var profile Profile
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal profile: %w", err)
+	}
+	return &profile, nil
+}
@@ -0,0 +1,16 @@
+package store_test
+
+import (
+	"testing"
+
+	"github.com/obasekietinosa/lockpick-api/internal/store"
+	"github.com/obasekietinosa/lockpick-api/internal/store/storetest"
+)
+
+func TestMemoryStore_Contract(t *testing.T) {
+	newStore := func(t *testing.T) store.Store {
+		return store.NewMemoryStore()
+	}
+	storetest.Run(t, newStore)
+	storetest.RunConcurrent(t, newStore)
+}
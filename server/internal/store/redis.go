@@ -3,16 +3,20 @@ package store
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
 type RedisStore struct {
 	client *redis.Client
+	ttl    TTLConfig
 }
 
-func NewRedisStore(addr, password string) (*RedisStore, error) {
+func NewRedisStore(addr, password string, ttl TTLConfig) (*RedisStore, error) {
 	client := redis.NewClient(&redis.Options{
 		Addr:     addr,
 		Password: password,
@@ -23,17 +27,83 @@ func NewRedisStore(addr, password string) (*RedisStore, error) {
 		return nil, fmt.Errorf("failed to connect to redis: %w", err)
 	}
 
-	return &RedisStore{client: client}, nil
+	return &RedisStore{client: client, ttl: ttl}, nil
 }
 
+// saveRoomScript stores a room, or a profile, only if the stored version matches the one it was read at.
+// KEYS: room. ARGV: expected version, room JSON, TTL in milliseconds. Returns 0 on a version mismatch.
+var saveRoomScript = redis.NewScript(`
+local current = redis.call("GET", KEYS[1])
+local version = 0
+if current then
+	version = cjson.decode(current).version or 0
+end
+if version ~= tonumber(ARGV[1]) then
+	return 0
+end
+local ttl = tonumber(ARGV[3])
+if ttl > 0 then
+	redis.call("SET", KEYS[1], ARGV[2], "PX", ttl)
+else
+	redis.call("SET", KEYS[1], ARGV[2])
+end
+return 1
+`)
+
 func (s *RedisStore) SaveRoom(ctx context.Context, room *Room) error {
+	expected := room.Version
+	room.Version++
 	data, err := json.Marshal(room)
 	if err != nil {
+		room.Version = expected
 		return fmt.Errorf("failed to marshal room: %w", err)
 	}
 
+	// Every save counts as activity, so the room's keys get a fresh TTL for its current state
+	ttl := s.ttl.ForStatus(room.Status)
 	key := fmt.Sprintf("room:%s", room.ID)
-	return s.client.Set(ctx, key, data, 0).Err()
+	saved, err := saveRoomScript.Run(ctx, s.client, []string{key}, expected, data, ttl.Milliseconds()).Int()
+	if err != nil {
+		room.Version = expected
+		return fmt.Errorf("failed to save room: %w", err)
+	}
+	if saved == 0 {
+		room.Version = expected
+		return ErrConflict
+	}
+
+	// The room is saved by now, so callers must not see a failed refresh as a failed save.
+	// The keys keep their old TTL until the next save refreshes them.
+	if err := s.refreshRoomKeys(ctx, room, ttl); err != nil {
+		log.Printf("Error refreshing TTLs of room %s: %v", room.ID, err)
+	}
+	return nil
+}
+
+// refreshRoomKeys extends the TTL of the keys that belong to a room: its player set, its players and its guess history
+func (s *RedisStore) refreshRoomKeys(ctx context.Context, room *Room, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+
+	playersKey := fmt.Sprintf("room:%s:players", room.ID)
+	players, err := s.client.SMembers(ctx, playersKey).Result()
+	if err != nil {
+		return fmt.Errorf("failed to get room players: %w", err)
+	}
+
+	pipe := s.client.Pipeline()
+	pipe.Expire(ctx, playersKey, ttl)
+	for _, playerID := range players {
+		pipe.Expire(ctx, fmt.Sprintf("player:%s", playerID), ttl)
+	}
+	for round := 1; round <= room.Config.TotalRounds(); round++ {
+		pipe.Expire(ctx, fmt.Sprintf("room:%s:round:%d:guesses", room.ID, round), ttl)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to refresh room TTLs: %w", err)
+	}
+	return nil
 }
 
 func (s *RedisStore) GetRoom(ctx context.Context, roomID string) (*Room, error) {
@@ -41,7 +111,7 @@ func (s *RedisStore) GetRoom(ctx context.Context, roomID string) (*Room, error)
 	data, err := s.client.Get(ctx, key).Bytes()
 	if err != nil {
 		if err == redis.Nil {
-			return nil, fmt.Errorf("room not found")
+			return nil, ErrRoomNotFound
 		}
 		return nil, fmt.Errorf("failed to get room: %w", err)
 	}
@@ -60,8 +130,17 @@ func (s *RedisStore) SavePlayer(ctx context.Context, player *Player) error {
 		return fmt.Errorf("failed to marshal player: %w", err)
 	}
 
+	// Refreshed to the room's TTL right after, and whenever the room is saved
 	key := fmt.Sprintf("player:%s", player.ID)
-	return s.client.Set(ctx, key, data, 0).Err()
+	if err := s.client.Set(ctx, key, data, s.ttl.Playing).Err(); err != nil {
+		return err
+	}
+
+	// Choosing pins does not save the room, but still counts as activity in it
+	if player.RoomID != "" {
+		s.touchRoom(ctx, player.RoomID)
+	}
+	return nil
 }
 
 func (s *RedisStore) GetPlayer(ctx context.Context, playerID string) (*Player, error) {
@@ -69,7 +148,7 @@ func (s *RedisStore) GetPlayer(ctx context.Context, playerID string) (*Player, e
 	data, err := s.client.Get(ctx, key).Bytes()
 	if err != nil {
 		if err == redis.Nil {
-			return nil, fmt.Errorf("player not found")
+			return nil, ErrPlayerNotFound
 		}
 		return nil, fmt.Errorf("failed to get player: %w", err)
 	}
@@ -84,7 +163,92 @@ func (s *RedisStore) GetPlayer(ctx context.Context, playerID string) (*Player, e
 
 func (s *RedisStore) AddPlayerToRoom(ctx context.Context, roomID, playerID string) error {
 	key := fmt.Sprintf("room:%s:players", roomID)
-	return s.client.SAdd(ctx, key, playerID).Err()
+	pipe := s.client.TxPipeline()
+	pipe.SAdd(ctx, key, playerID)
+	if s.ttl.Playing > 0 {
+		pipe.Expire(ctx, key, s.ttl.Playing)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// joinRoomScript checks the room's capacity and adds the player in a single step.
+// KEYS: room, room players, player. ARGV: player ID, max players, player JSON, TTL in milliseconds.
+// Returns -1 if the room does not exist, 0 if it is full and 1 once the player has joined.
+var joinRoomScript = redis.NewScript(`
+local room = redis.call("GET", KEYS[1])
+if not room then
+	return -1
+end
+if cjson.decode(room).status ~= "waiting" then
+	return -2
+end
+if redis.call("SISMEMBER", KEYS[2], ARGV[1]) == 0 and redis.call("SCARD", KEYS[2]) >= tonumber(ARGV[2]) then
+	return 0
+end
+redis.call("SADD", KEYS[2], ARGV[1])
+local ttl = tonumber(ARGV[4])
+if ttl > 0 then
+	redis.call("SET", KEYS[3], ARGV[3], "PX", ttl)
+	redis.call("PEXPIRE", KEYS[2], ttl)
+else
+	redis.call("SET", KEYS[3], ARGV[3])
+end
+return 1
+`)
+
+func (s *RedisStore) JoinRoom(ctx context.Context, roomID string, player *Player, maxPlayers int) error {
+	data, err := json.Marshal(player)
+	if err != nil {
+		return fmt.Errorf("failed to marshal player: %w", err)
+	}
+
+	keys := []string{
+		fmt.Sprintf("room:%s", roomID),
+		fmt.Sprintf("room:%s:players", roomID),
+		fmt.Sprintf("player:%s", player.ID),
+	}
+	result, err := joinRoomScript.Run(ctx, s.client, keys, player.ID, maxPlayers, data, s.ttl.Playing.Milliseconds()).Int()
+	if err != nil {
+		return fmt.Errorf("failed to join room: %w", err)
+	}
+
+	switch result {
+	case -1:
+		return ErrRoomNotFound
+	case -2:
+		return ErrRoomNotWaiting
+	case 0:
+		return ErrRoomFull
+	}
+
+	s.touchRoom(ctx, roomID)
+	return nil
+}
+
+// touchRoom gives a room and its keys a fresh TTL for the room's current state, after activity that
+// does not save the room. The activity itself has succeeded by then, so errors are only logged.
+func (s *RedisStore) touchRoom(ctx context.Context, roomID string) {
+	room, err := s.GetRoom(ctx, roomID)
+	if errors.Is(err, ErrRoomNotFound) {
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting room %s to refresh its TTLs: %v", roomID, err)
+		return
+	}
+
+	ttl := s.ttl.ForStatus(room.Status)
+	if ttl <= 0 {
+		return
+	}
+	if err := s.client.PExpire(ctx, fmt.Sprintf("room:%s", roomID), ttl).Err(); err != nil {
+		log.Printf("Error refreshing TTL of room %s: %v", roomID, err)
+		return
+	}
+	if err := s.refreshRoomKeys(ctx, room, ttl); err != nil {
+		log.Printf("Error refreshing TTLs of room %s: %v", roomID, err)
+	}
 }
 
 func (s *RedisStore) GetRoomPlayers(ctx context.Context, roomID string) ([]string, error) {
@@ -92,42 +256,77 @@ func (s *RedisStore) GetRoomPlayers(ctx context.Context, roomID string) ([]strin
 	return s.client.SMembers(ctx, key).Result()
 }
 
-func (s *RedisStore) FindMatchingRoom(ctx context.Context, config *GameConfig) (*Room, error) {
-	key := fmt.Sprintf("waiting:%d:%v:%d", config.PinLength, config.HintsEnabled, config.TimerDuration)
-	roomID, err := s.client.SPop(ctx, key).Result()
+func (s *RedisStore) AppendGuess(ctx context.Context, roomID string, round int, guess *GuessRecord) error {
+	data, err := json.Marshal(guess)
 	if err != nil {
-		if err == redis.Nil {
-			return nil, nil // No matching room found
-		}
-		return nil, fmt.Errorf("failed to find matching room: %w", err)
+		return fmt.Errorf("failed to marshal guess: %w", err)
 	}
 
-	return s.GetRoom(ctx, roomID)
+	key := fmt.Sprintf("room:%s:round:%d:guesses", roomID, round)
+	pipe := s.client.TxPipeline()
+	pipe.RPush(ctx, key, data)
+	if s.ttl.Playing > 0 {
+		pipe.Expire(ctx, key, s.ttl.Playing)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
 }
 
-func (s *RedisStore) AddWaitingRoom(ctx context.Context, room *Room) error {
-	if room.Config == nil {
-		return fmt.Errorf("room config is nil")
+func (s *RedisStore) ListGuesses(ctx context.Context, roomID string, round int) ([]*GuessRecord, error) {
+	key := fmt.Sprintf("room:%s:round:%d:guesses", roomID, round)
+	items, err := s.client.LRange(ctx, key, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list guesses: %w", err)
+	}
+
+	guesses := make([]*GuessRecord, 0, len(items))
+	for _, item := range items {
+		var guess GuessRecord
+		if err := json.Unmarshal([]byte(item), &guess); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal guess: %w", err)
+		}
+		guesses = append(guesses, &guess)
 	}
-	key := fmt.Sprintf("waiting:%d:%v:%d", room.Config.PinLength, room.Config.HintsEnabled, room.Config.TimerDuration)
-	return s.client.SAdd(ctx, key, room.ID).Err()
+
+	return guesses, nil
 }
 
-func (s *RedisStore) RemoveWaitingRoom(ctx context.Context, roomID string) error {
-	// This is a bit inefficient as we don't know the config just from ID.
-	// But in the "Join" flow we usually have the room object or at least we can fetch it.
-	// For now, let's assume we might need to fetch the room first if we want to remove it from waiting list.
-	// However, the interface asks for roomID.
+func (s *RedisStore) SaveProfile(ctx context.Context, profile *Profile) error {
+	expected := profile.Version
+	profile.Version++
+	data, err := json.Marshal(profile)
+	if err != nil {
+		profile.Version = expected
+		return fmt.Errorf("failed to marshal profile: %w", err)
+	}
 
-	// Optimization: If we fetch the room, we can construct the key.
-	room, err := s.GetRoom(ctx, roomID)
+	// Profiles outlive the games they were played in, so they are stored without a TTL
+	key := fmt.Sprintf("profile:%s", profile.ID)
+	saved, err := saveRoomScript.Run(ctx, s.client, []string{key}, expected, data, 0).Int()
 	if err != nil {
-		return err
+		profile.Version = expected
+		return fmt.Errorf("failed to save profile: %w", err)
 	}
-	if room.Config == nil {
-		return nil // Should be an error?
+	if saved == 0 {
+		profile.Version = expected
+		return ErrConflict
+	}
+	return nil
+}
+
+func (s *RedisStore) GetProfile(ctx context.Context, profileID string) (*Profile, error) {
+	key := fmt.Sprintf("profile:%s", profileID)
+	data, err := s.client.Get(ctx, key).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrProfileNotFound
+		}
+		return nil, fmt.Errorf("failed to get profile: %w", err)
 	}
 
-	key := fmt.Sprintf("waiting:%d:%v:%d", room.Config.PinLength, room.Config.HintsEnabled, room.Config.TimerDuration)
-	return s.client.SRem(ctx, key, roomID).Err()
+	var profile Profile
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal profile: %w", err)
+	}
+	return &profile, nil
 }
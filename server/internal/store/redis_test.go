@@ -0,0 +1,87 @@
+package store_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/obasekietinosa/lockpick-api/internal/store"
+	"github.com/obasekietinosa/lockpick-api/internal/store/storetest"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestRedisStore connects to the Redis server in REDIS_TEST_ADDR, skipping the test if it is not set.
+// The database is flushed, so never point it at a server holding real data.
+func newTestRedisStore(t *testing.T) *store.RedisStore {
+	t.Helper()
+	addr := os.Getenv("REDIS_TEST_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_TEST_ADDR is not set")
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	defer client.Close()
+	if err := client.FlushDB(context.Background()).Err(); err != nil {
+		t.Fatalf("Failed to flush Redis: %v", err)
+	}
+
+	s, err := store.NewRedisStore(addr, "", store.TTLConfig{})
+	if err != nil {
+		t.Fatalf("Failed to connect to Redis: %v", err)
+	}
+	return s
+}
+
+func TestRedisStore_Contract(t *testing.T) {
+	newStore := func(t *testing.T) store.Store {
+		return newTestRedisStore(t)
+	}
+	storetest.Run(t, newStore)
+	storetest.RunConcurrent(t, newStore)
+}
+
+func TestRedisStore_ActivityRefreshesWaitingRoom(t *testing.T) {
+	newTestRedisStore(t) // Flushes the database
+	ctx := context.Background()
+	addr := os.Getenv("REDIS_TEST_ADDR")
+
+	s, err := store.NewRedisStore(addr, "", store.TTLConfig{Waiting: time.Minute, Playing: time.Hour})
+	if err != nil {
+		t.Fatalf("Failed to connect to Redis: %v", err)
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	defer client.Close()
+
+	room := &store.Room{ID: "room1", Status: "waiting", Config: &store.GameConfig{PinLength: 5}}
+	if err := s.SaveRoom(ctx, room); err != nil {
+		t.Fatalf("SaveRoom: %v", err)
+	}
+
+	// A lobby that has been open for a while, whose room is about to expire
+	expireSoon := func() {
+		if err := client.PExpire(ctx, "room:room1", time.Second).Err(); err != nil {
+			t.Fatalf("PExpire: %v", err)
+		}
+	}
+	refreshed := func(step string) {
+		ttl, err := client.PTTL(ctx, "room:room1").Result()
+		if err != nil || ttl <= time.Second {
+			t.Errorf("Expected %s to refresh the room TTL, got %v, %v", step, ttl, err)
+		}
+	}
+
+	expireSoon()
+	player := &store.Player{ID: "p1", RoomID: room.ID}
+	if err := s.JoinRoom(ctx, room.ID, player, 2); err != nil {
+		t.Fatalf("JoinRoom: %v", err)
+	}
+	refreshed("joining")
+
+	expireSoon()
+	player.Pins = []string{"12345"}
+	if err := s.SavePlayer(ctx, player); err != nil {
+		t.Fatalf("SavePlayer: %v", err)
+	}
+	refreshed("choosing pins")
+}
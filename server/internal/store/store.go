@@ -2,9 +2,32 @@ package store
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 )
 
+// Errors returned by Store implementations. Callers should compare with errors.Is.
+var (
+	ErrRoomNotFound   = errors.New("room not found")
+	ErrPlayerNotFound = errors.New("player not found")
+	// ErrRoomFull is returned when a player tries to join a room that already has its maximum number of players
+	ErrRoomFull = errors.New("room is full")
+	// ErrRoomNotWaiting is returned when a player tries to join a room whose game has already started or ended
+	ErrRoomNotWaiting = errors.New("room is not waiting for players")
+	// ErrConflict is returned when a write loses a race with a concurrent write
+	ErrConflict = errors.New("conflicting update")
+	// ErrProfileNotFound is returned when no profile has the given ID
+	ErrProfileNotFound = errors.New("profile not found")
+)
+
+const (
+	// DefaultRounds is the number of rounds played when GameConfig.Rounds is not set
+	DefaultRounds = 3
+	// MaxRounds is the largest number of rounds a game can be configured with
+	MaxRounds = 9
+)
+
 // GameConfig holds the configuration for a game session
 type GameConfig struct {
 	PlayerName    string `json:"player_name"`
@@ -12,6 +35,25 @@ type GameConfig struct {
 	PinLength     int    `json:"pin_length"`
 	TimerDuration int    `json:"timer_duration"` // 0 means no timer
 	IsPrivate     bool   `json:"is_private"`
+	HintMode      string `json:"hint_mode,omitempty"` // e.g. "positional" (default), "mastermind"
+	Mode          string `json:"mode,omitempty"`      // "versus" (default) or "solo"
+	Rounds        int    `json:"rounds,omitempty"`    // Number of rounds (best-of-N), 0 means DefaultRounds
+	TurnBased     bool   `json:"turn_based"`          // Players alternate guesses instead of racing
+	TurnDuration  int    `json:"turn_duration"`       // Seconds per turn in turn-based games, 0 means no turn timer
+	MaxGuesses    int    `json:"max_guesses"`         // Guesses allowed per player per round, 0 means unlimited
+}
+
+// MatchKey identifies the settings that must be equal for two players to be matched into the same room
+func (c *GameConfig) MatchKey() string {
+	return fmt.Sprintf("%d:%v:%d:%s:%d:%v:%d:%d", c.PinLength, c.HintsEnabled, c.TimerDuration, c.HintMode, c.TotalRounds(), c.TurnBased, c.TurnDuration, c.MaxGuesses)
+}
+
+// TotalRounds returns the number of rounds in the game
+func (c *GameConfig) TotalRounds() int {
+	if c == nil || c.Rounds <= 0 {
+		return DefaultRounds
+	}
+	return c.Rounds
 }
 
 // Player represents a participant in the game
@@ -19,7 +61,38 @@ type Player struct {
 	ID     string   `json:"id"`
 	Name   string   `json:"name"`
 	RoomID string   `json:"room_id"`
-	Pins   []string `json:"pins"`
+	Pins   []string `json:"pins,omitempty"`
+
+	// ProfileID links the player to the profile whose rating the game counts towards
+	ProfileID string `json:"profile_id,omitempty"`
+
+	// SealedPins replaces Pins in storage when the store is wrapped in an EncryptedStore
+	SealedPins *SealedPins `json:"sealed_pins,omitempty"`
+}
+
+// Profile is a player's identity across games. Unlike Player, which only lives as long as its room,
+// a profile is kept until it is deleted, and carries the player's Glicko-2 rating.
+type Profile struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Rating      float64   `json:"rating"`
+	Deviation   float64   `json:"rating_deviation"`
+	Volatility  float64   `json:"volatility"`
+	GamesPlayed int       `json:"games_played"`
+	Wins        int       `json:"wins"`
+	Losses      int       `json:"losses"`
+	Draws       int       `json:"draws"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Version works like Room.Version, so results from two games finishing together are both counted
+	Version int64 `json:"version"`
+}
+
+// PlayerStats tracks the guesses a player used and how long they took
+type PlayerStats struct {
+	Attempts    int   `json:"attempts"`
+	TimeTakenMs int64 `json:"time_taken_ms"` // Time from round start to the player's last guess
 }
 
 // Room represents a game room
@@ -28,21 +101,83 @@ type Room struct {
 	HostID       string         `json:"host_id"`
 	Status       string         `json:"status"` // e.g., "waiting", "playing", "finished"
 	Config       *GameConfig    `json:"config"`
-	CurrentRound int            `json:"current_round"` // 1-indexed (1, 2, 3)
+	CurrentRound int            `json:"current_round"` // 1-indexed (1 to Config.TotalRounds())
 	Scores       map[string]int `json:"scores"`        // PlayerID -> Score (Rounds won)
 	CreatedAt    time.Time      `json:"created_at"`
 	ReadyPlayers []string       `json:"ready_players"`
+	RoundActive  bool           `json:"round_active"`           // True between round_start and round_end
+	CurrentTurn  string         `json:"current_turn,omitempty"` // PlayerID allowed to guess in turn-based games
+
+	RoundStartedAt time.Time               `json:"round_started_at"`
+	RoundStats     map[string]*PlayerStats `json:"round_stats,omitempty"`  // PlayerID -> Stats for the current round
+	GameStats      map[string]*PlayerStats `json:"game_stats,omitempty"`   // PlayerID -> Totals across finished rounds
+	SolvedStats    map[string]*PlayerStats `json:"solved_stats,omitempty"` // PlayerID -> Totals across the rounds the player won
+
+	// Version is incremented by every successful SaveRoom. Saving a room whose Version no longer
+	// matches the stored one fails with ErrConflict, so concurrent updates cannot overwrite each other.
+	Version int64 `json:"version"`
+}
+
+// GuessRecord is a guess made during a round together with the feedback it received
+type GuessRecord struct {
+	PlayerID  string      `json:"player_id"`
+	Guess     string      `json:"guess"`
+	HintMode  string      `json:"hint_mode"`
+	Hints     interface{} `json:"hints"` // Shape depends on HintMode
+	CreatedAt time.Time   `json:"created_at"`
 }
 
 // Store defines the interface for data persistence
 type Store interface {
+	// SaveRoom stores the room if its Version matches the stored room, or is 0 for a new room,
+	// and increments room.Version. It returns ErrConflict if the room was saved by someone else in the meantime.
 	SaveRoom(ctx context.Context, room *Room) error
 	GetRoom(ctx context.Context, roomID string) (*Room, error)
 	SavePlayer(ctx context.Context, player *Player) error
 	GetPlayer(ctx context.Context, playerID string) (*Player, error)
 	AddPlayerToRoom(ctx context.Context, roomID, playerID string) error
+	// JoinRoom saves the player and adds them to the room in one atomic step, as long as the
+	// room has fewer than maxPlayers players. It returns ErrRoomFull otherwise.
+	JoinRoom(ctx context.Context, roomID string, player *Player, maxPlayers int) error
 	GetRoomPlayers(ctx context.Context, roomID string) ([]string, error)
-	FindMatchingRoom(ctx context.Context, config *GameConfig) (*Room, error)
-	AddWaitingRoom(ctx context.Context, room *Room) error
-	RemoveWaitingRoom(ctx context.Context, roomID string) error
+	AppendGuess(ctx context.Context, roomID string, round int, guess *GuessRecord) error
+	ListGuesses(ctx context.Context, roomID string, round int) ([]*GuessRecord, error)
+	// SaveProfile stores the profile with the same version check as SaveRoom
+	SaveProfile(ctx context.Context, profile *Profile) error
+	GetProfile(ctx context.Context, profileID string) (*Profile, error)
+}
+
+// MaxConflictRetries is how many times RetryOnConflict runs an update before giving up
+const MaxConflictRetries = 3
+
+// RetryOnConflict runs fn until it returns an error other than ErrConflict, or MaxConflictRetries times.
+// fn must read the room or profile again on every run, and only cause side effects once its save has succeeded.
+func RetryOnConflict(fn func() error) error {
+	var err error
+	for attempt := 0; attempt < MaxConflictRetries; attempt++ {
+		if err = fn(); !errors.Is(err, ErrConflict) {
+			return err
+		}
+	}
+	return err
+}
+
+// TTLConfig controls how long a game's keys live in each stage of its lifecycle.
+// A zero duration keeps keys until they are deleted.
+type TTLConfig struct {
+	Waiting  time.Duration // Rooms waiting for an opponent, and their matchmaking entries
+	Playing  time.Duration // Games in progress
+	Finished time.Duration // Finished games, kept around for their history
+}
+
+// ForStatus returns the TTL for a room with the given status
+func (t TTLConfig) ForStatus(status string) time.Duration {
+	switch status {
+	case "waiting":
+		return t.Waiting
+	case "finished":
+		return t.Finished
+	default:
+		return t.Playing
+	}
 }
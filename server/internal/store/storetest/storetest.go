@@ -0,0 +1,383 @@
+// Package storetest is a contract test suite that every store.Store implementation must pass.
+package storetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/obasekietinosa/lockpick-api/internal/store"
+)
+
+// Run runs the contract tests, calling newStore for an empty store in each subtest.
+func Run(t *testing.T, newStore func(t *testing.T) store.Store) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s store.Store)
+	}{
+		{"Rooms", testRooms},
+		{"RoomVersions", testRoomVersions},
+		{"Players", testPlayers},
+		{"JoinRoom", testJoinRoom},
+		{"Guesses", testGuesses},
+		{"Profiles", testProfiles},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newStore(t))
+		})
+	}
+}
+
+// RunConcurrent runs the contract tests that need a store that is safe for concurrent use.
+func RunConcurrent(t *testing.T, newStore func(t *testing.T) store.Store) {
+	t.Run("JoinRoomConcurrent", func(t *testing.T) {
+		testJoinRoomConcurrent(t, newStore(t))
+	})
+	t.Run("SaveRoomConcurrent", func(t *testing.T) {
+		testSaveRoomConcurrent(t, newStore(t))
+	})
+}
+
+func newRoom(id, status string, config *store.GameConfig) *store.Room {
+	return &store.Room{
+		ID:           id,
+		HostID:       id + "-host",
+		Status:       status,
+		Config:       config,
+		CurrentRound: 1,
+		Scores:       map[string]int{},
+		CreatedAt:    time.Now(),
+	}
+}
+
+func testRooms(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	if _, err := s.GetRoom(ctx, "missing"); !errors.Is(err, store.ErrRoomNotFound) {
+		t.Fatalf("Expected ErrRoomNotFound for a missing room, got %v", err)
+	}
+
+	room := newRoom("room1", "waiting", &store.GameConfig{PinLength: 5, Rounds: 5})
+	room.Scores["room1-host"] = 2
+	if err := s.SaveRoom(ctx, room); err != nil {
+		t.Fatalf("SaveRoom: %v", err)
+	}
+
+	got, err := s.GetRoom(ctx, room.ID)
+	if err != nil {
+		t.Fatalf("GetRoom: %v", err)
+	}
+	if got.ID != room.ID || got.HostID != room.HostID || got.Status != room.Status {
+		t.Errorf("Expected room %+v, got %+v", room, got)
+	}
+	if got.Config == nil || got.Config.PinLength != 5 || got.Config.TotalRounds() != 5 {
+		t.Errorf("Expected config to round-trip, got %+v", got.Config)
+	}
+	if got.Scores["room1-host"] != 2 {
+		t.Errorf("Expected scores to round-trip, got %v", got.Scores)
+	}
+}
+
+func testRoomVersions(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	room := newRoom("room1", "waiting", &store.GameConfig{PinLength: 5})
+	if err := s.SaveRoom(ctx, room); err != nil {
+		t.Fatalf("SaveRoom: %v", err)
+	}
+	if room.Version != 1 {
+		t.Errorf("Expected version 1 after the first save, got %d", room.Version)
+	}
+
+	// Creating a room that already exists is a conflict
+	if err := s.SaveRoom(ctx, newRoom("room1", "waiting", &store.GameConfig{PinLength: 5})); !errors.Is(err, store.ErrConflict) {
+		t.Errorf("Expected ErrConflict saving a new room over an existing one, got %v", err)
+	}
+
+	first, err := s.GetRoom(ctx, room.ID)
+	if err != nil {
+		t.Fatalf("GetRoom: %v", err)
+	}
+	second, err := s.GetRoom(ctx, room.ID)
+	if err != nil {
+		t.Fatalf("GetRoom: %v", err)
+	}
+
+	first.Status = "playing"
+	if err := s.SaveRoom(ctx, first); err != nil {
+		t.Fatalf("SaveRoom: %v", err)
+	}
+	if first.Version != 2 {
+		t.Errorf("Expected version 2 after the second save, got %d", first.Version)
+	}
+
+	second.Status = "finished"
+	if err := s.SaveRoom(ctx, second); !errors.Is(err, store.ErrConflict) {
+		t.Fatalf("Expected ErrConflict saving a stale room, got %v", err)
+	}
+	if second.Version != 1 {
+		t.Errorf("Expected a failed save to leave the version at 1, got %d", second.Version)
+	}
+
+	got, err := s.GetRoom(ctx, room.ID)
+	if err != nil {
+		t.Fatalf("GetRoom: %v", err)
+	}
+	if got.Status != "playing" || got.Version != 2 {
+		t.Errorf("Expected the first update to win, got status %s at version %d", got.Status, got.Version)
+	}
+
+	// Saving again with the updated version succeeds
+	first.Status = "finished"
+	if err := s.SaveRoom(ctx, first); err != nil {
+		t.Errorf("Expected saving the latest version to succeed, got %v", err)
+	}
+}
+
+func testPlayers(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	if _, err := s.GetPlayer(ctx, "missing"); !errors.Is(err, store.ErrPlayerNotFound) {
+		t.Fatalf("Expected ErrPlayerNotFound for a missing player, got %v", err)
+	}
+
+	player := &store.Player{ID: "p1", Name: "Player 1", RoomID: "room1", Pins: []string{"12345"}}
+	if err := s.SavePlayer(ctx, player); err != nil {
+		t.Fatalf("SavePlayer: %v", err)
+	}
+
+	got, err := s.GetPlayer(ctx, player.ID)
+	if err != nil {
+		t.Fatalf("GetPlayer: %v", err)
+	}
+	if got.Name != player.Name || got.RoomID != player.RoomID || len(got.Pins) != 1 || got.Pins[0] != "12345" {
+		t.Errorf("Expected player %+v, got %+v", player, got)
+	}
+}
+
+func testJoinRoom(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	if err := s.JoinRoom(ctx, "missing", &store.Player{ID: "p1", RoomID: "missing"}, 2); !errors.Is(err, store.ErrRoomNotFound) {
+		t.Fatalf("Expected ErrRoomNotFound joining a missing room, got %v", err)
+	}
+
+	room := newRoom("room1", "waiting", &store.GameConfig{PinLength: 5})
+	if err := s.SaveRoom(ctx, room); err != nil {
+		t.Fatalf("SaveRoom: %v", err)
+	}
+
+	for _, id := range []string{"p1", "p2"} {
+		if err := s.JoinRoom(ctx, room.ID, &store.Player{ID: id, RoomID: room.ID}, 2); err != nil {
+			t.Fatalf("JoinRoom %s: %v", id, err)
+		}
+	}
+
+	if err := s.JoinRoom(ctx, room.ID, &store.Player{ID: "p3", RoomID: room.ID}, 2); !errors.Is(err, store.ErrRoomFull) {
+		t.Errorf("Expected ErrRoomFull joining a full room, got %v", err)
+	}
+
+	// Joining again is not counted twice
+	if err := s.JoinRoom(ctx, room.ID, &store.Player{ID: "p2", Name: "Renamed", RoomID: room.ID}, 2); err != nil {
+		t.Errorf("Expected a member to be able to rejoin, got %v", err)
+	}
+
+	players, err := s.GetRoomPlayers(ctx, room.ID)
+	if err != nil {
+		t.Fatalf("GetRoomPlayers: %v", err)
+	}
+	if len(players) != 2 {
+		t.Errorf("Expected 2 players, got %v", players)
+	}
+
+	if _, err := s.GetPlayer(ctx, "p1"); err != nil {
+		t.Errorf("Expected joined player to be saved, got %v", err)
+	}
+	if _, err := s.GetPlayer(ctx, "p3"); !errors.Is(err, store.ErrPlayerNotFound) {
+		t.Errorf("Expected rejected player not to be saved, got %v", err)
+	}
+
+	// A game that has started or ended cannot be joined, even with a free seat
+	for _, status := range []string{"playing", "finished"} {
+		room := newRoom("room-"+status, status, &store.GameConfig{PinLength: 5})
+		if err := s.SaveRoom(ctx, room); err != nil {
+			t.Fatalf("SaveRoom: %v", err)
+		}
+		if err := s.JoinRoom(ctx, room.ID, &store.Player{ID: "late", RoomID: room.ID}, 2); !errors.Is(err, store.ErrRoomNotWaiting) {
+			t.Errorf("Expected ErrRoomNotWaiting joining a %s room, got %v", status, err)
+		}
+	}
+	if _, err := s.GetPlayer(ctx, "late"); !errors.Is(err, store.ErrPlayerNotFound) {
+		t.Errorf("Expected rejected player not to be saved, got %v", err)
+	}
+}
+
+func testGuesses(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	guesses, err := s.ListGuesses(ctx, "room1", 1)
+	if err != nil {
+		t.Fatalf("ListGuesses: %v", err)
+	}
+	if len(guesses) != 0 {
+		t.Errorf("Expected no guesses, got %d", len(guesses))
+	}
+
+	for _, g := range []string{"11111", "22222"} {
+		if err := s.AppendGuess(ctx, "room1", 1, &store.GuessRecord{PlayerID: "p1", Guess: g, HintMode: "positional"}); err != nil {
+			t.Fatalf("AppendGuess: %v", err)
+		}
+	}
+	if err := s.AppendGuess(ctx, "room1", 2, &store.GuessRecord{PlayerID: "p1", Guess: "33333"}); err != nil {
+		t.Fatalf("AppendGuess: %v", err)
+	}
+
+	guesses, err = s.ListGuesses(ctx, "room1", 1)
+	if err != nil {
+		t.Fatalf("ListGuesses: %v", err)
+	}
+	if len(guesses) != 2 || guesses[0].Guess != "11111" || guesses[1].Guess != "22222" {
+		t.Errorf("Expected round 1 guesses in order, got %+v", guesses)
+	}
+}
+
+func testJoinRoomConcurrent(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	room := newRoom("room1", "waiting", &store.GameConfig{PinLength: 5})
+	if err := s.SaveRoom(ctx, room); err != nil {
+		t.Fatalf("SaveRoom: %v", err)
+	}
+	if err := s.JoinRoom(ctx, room.ID, &store.Player{ID: room.HostID, RoomID: room.ID}, 2); err != nil {
+		t.Fatalf("Host JoinRoom: %v", err)
+	}
+
+	const joiners = 20
+	errs := make(chan error, joiners)
+	var wg sync.WaitGroup
+	for i := 0; i < joiners; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- s.JoinRoom(ctx, room.ID, &store.Player{ID: fmt.Sprintf("p%d", i), RoomID: room.ID}, 2)
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	joined, full := 0, 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			joined++
+		case errors.Is(err, store.ErrRoomFull):
+			full++
+		default:
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+	if joined != 1 || full != joiners-1 {
+		t.Errorf("Expected 1 join and %d ErrRoomFull, got %d joins and %d ErrRoomFull", joiners-1, joined, full)
+	}
+
+	players, err := s.GetRoomPlayers(ctx, room.ID)
+	if err != nil {
+		t.Fatalf("GetRoomPlayers: %v", err)
+	}
+	if len(players) != 2 {
+		t.Errorf("Expected 2 players in room, got %d", len(players))
+	}
+}
+
+func testSaveRoomConcurrent(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	room := newRoom("room1", "playing", &store.GameConfig{PinLength: 5})
+	if err := s.SaveRoom(ctx, room); err != nil {
+		t.Fatalf("SaveRoom: %v", err)
+	}
+
+	// Every writer keeps retrying until its increment lands, so none are lost
+	const writers = 10
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				err := store.RetryOnConflict(func() error {
+					r, err := s.GetRoom(ctx, room.ID)
+					if err != nil {
+						return err
+					}
+					r.Scores["p1"]++
+					return s.SaveRoom(ctx, r)
+				})
+				if !errors.Is(err, store.ErrConflict) {
+					if err != nil {
+						t.Errorf("Unexpected error: %v", err)
+					}
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	got, err := s.GetRoom(ctx, room.ID)
+	if err != nil {
+		t.Fatalf("GetRoom: %v", err)
+	}
+	if got.Scores["p1"] != writers {
+		t.Errorf("Expected %d increments, got %d", writers, got.Scores["p1"])
+	}
+	if got.Version != writers+1 {
+		t.Errorf("Expected version %d, got %d", writers+1, got.Version)
+	}
+}
+
+func testProfiles(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	if _, err := s.GetProfile(ctx, "missing"); !errors.Is(err, store.ErrProfileNotFound) {
+		t.Fatalf("Expected ErrProfileNotFound for a missing profile, got %v", err)
+	}
+
+	profile := &store.Profile{ID: "profile1", Name: "Player 1", Rating: 1500, Deviation: 350, Volatility: 0.06}
+	if err := s.SaveProfile(ctx, profile); err != nil {
+		t.Fatalf("SaveProfile: %v", err)
+	}
+
+	stale, err := s.GetProfile(ctx, profile.ID)
+	if err != nil {
+		t.Fatalf("GetProfile: %v", err)
+	}
+	if stale.Name != "Player 1" || stale.Rating != 1500 || stale.Version != 1 {
+		t.Errorf("Expected the saved profile back, got %+v", stale)
+	}
+
+	profile.Rating = 1600
+	profile.GamesPlayed = 1
+	if err := s.SaveProfile(ctx, profile); err != nil {
+		t.Fatalf("SaveProfile: %v", err)
+	}
+
+	// A result counted from a stale read would undo the one saved before it
+	stale.Rating = 1400
+	if err := s.SaveProfile(ctx, stale); !errors.Is(err, store.ErrConflict) {
+		t.Fatalf("Expected ErrConflict saving a stale profile, got %v", err)
+	}
+
+	got, err := s.GetProfile(ctx, profile.ID)
+	if err != nil {
+		t.Fatalf("GetProfile: %v", err)
+	}
+	if got.Rating != 1600 || got.GamesPlayed != 1 || got.Version != 2 {
+		t.Errorf("Expected the second save to be kept, got %+v", got)
+	}
+}